@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -11,7 +13,12 @@ type Config struct {
 	TelegramBotToken string
 	WebhookURL       string
 	Port             string
-	Mode             string // "polling" or "webhook"
+	Mode             string  // "polling" or "webhook"
+	DataDir          string  // Directory for persistent bot state
+	APIURL           string  // Bot API server, e.g. a local telegram-bot-api
+	LocalBotAPI      bool    // Whether APIURL is a Local Bot API server
+	AllowedUsers     []int64 // User IDs allowed to use the bot; empty allows everyone
+	AdminChatID      int64   // Chat that receives error reports; 0 disables them
 }
 
 func Load() *Config {
@@ -32,10 +39,45 @@ func Load() *Config {
 		mode = "polling" // Default to polling
 	}
 
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = "data"
+	}
+
+	apiURL := os.Getenv("TELEGRAM_API_URL")
+	if apiURL == "" {
+		apiURL = "https://api.telegram.org"
+	}
+
+	var allowedUsers []int64
+	for _, field := range strings.Split(os.Getenv("ALLOWED_USERS"), ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid user ID %q in ALLOWED_USERS", field)
+		}
+		allowedUsers = append(allowedUsers, id)
+	}
+
+	var adminChatID int64
+	if value := os.Getenv("ADMIN_CHAT_ID"); value != "" {
+		adminChatID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid ADMIN_CHAT_ID %q", value)
+		}
+	}
+
 	return &Config{
 		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
 		WebhookURL:       os.Getenv("WEBHOOK_URL"),
 		Port:             port,
 		Mode:             mode,
+		DataDir:          dataDir,
+		APIURL:           apiURL,
+		LocalBotAPI:      os.Getenv("LOCAL_BOT_API") == "true",
+		AllowedUsers:     allowedUsers,
+		AdminChatID:      adminChatID,
 	}
 }
@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// DefaultMaxRetries is how often a request is retried after a 429 answer
+const DefaultMaxRetries = 3
+
+// apiRequest describes the body of a single Bot API request
+type apiRequest struct {
+	contentType   string
+	contentLength int64            // Set for streamed bodies of known size
+	body          func() io.Reader // Called once per attempt; nil means no body
+	timeout       time.Duration    // Zero means the client's default timeout
+	chatID        int64            // Target chat used for rate limiting, if any
+}
+
+// chatScoped is implemented by requests addressed to a single chat
+type chatScoped interface {
+	targetChatID() int64
+}
+
+// Call invokes a Bot API method and decodes the result into result.
+// params and result may be nil. params are sent as JSON, or as a streamed
+// multipart upload if they contain local files. A Local Bot API server
+// reads local files from disk itself. A response with ok=false is
+// returned as an *APIError.
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	var req apiRequest
+	var err error
+
+	if files := localFiles(params); len(files) > 0 && c.localServer {
+		req, err = c.newLocalFileRequest(params, files)
+	} else if len(files) > 0 {
+		req, err = c.newUploadRequest(ctx, params, files)
+	} else {
+		req, err = newJSONRequest(params)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	return c.call(ctx, method, req, result)
+}
+
+// newJSONRequest encodes params as a JSON request body
+func newJSONRequest(params any) (apiRequest, error) {
+	req := apiRequest{}
+	if params == nil {
+		return req, nil
+	}
+
+	jsonData, err := json.Marshal(params)
+	if err != nil {
+		return req, err
+	}
+	req.contentType = "application/json"
+	req.body = func() io.Reader { return bytes.NewReader(jsonData) }
+
+	if scoped, ok := params.(chatScoped); ok {
+		req.chatID = scoped.targetChatID()
+	}
+
+	return req, nil
+}
+
+// call sends req to the given method, waiting for the rate limiter and
+// retrying when Telegram asks us to back off
+func (c *Client) call(ctx context.Context, method string, req apiRequest, result any) error {
+	for attempt := 0; ; attempt++ {
+		// Requests without a chat only wait for the global bucket
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx, req.chatID); err != nil {
+				return err
+			}
+		}
+
+		err := c.callOnce(ctx, method, req, result)
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 || attempt >= c.maxRetries {
+			return err
+		}
+
+		log.Printf("Flood control on %s, retrying in %s", method, apiErr.RetryAfter)
+		if c.limiter != nil {
+			c.limiter.Pause(req.chatID, apiErr.RetryAfter)
+		}
+		if err := sleepContext(ctx, apiErr.RetryAfter); err != nil {
+			return err
+		}
+	}
+}
+
+// callOnce performs a single HTTP round trip and decodes the response envelope
+func (c *Client) callOnce(ctx context.Context, method string, req apiRequest, result any) error {
+	timeout := req.timeout
+	if timeout == 0 {
+		timeout = c.timeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if req.body != nil {
+		body = req.body()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, body)
+	if err != nil {
+		// Stop streamed bodies from writing into the void
+		if closer, ok := body.(io.Closer); ok {
+			closer.Close()
+		}
+		return fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if req.contentLength > 0 {
+		httpReq.ContentLength = req.contentLength
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+
+	var response APIResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		// Proxies and load balancers may answer with non-JSON bodies
+		if resp.StatusCode != http.StatusOK {
+			return &APIError{Method: method, Code: resp.StatusCode, Description: http.StatusText(resp.StatusCode)}
+		}
+		return fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+
+	if !response.Ok {
+		return newAPIError(method, &response)
+	}
+
+	if result == nil || len(response.Result) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("failed to parse %s result: %w", method, err)
+	}
+
+	return nil
+}
+
+// sleepContext pauses for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
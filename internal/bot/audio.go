@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hamond.dev/telegram-bot-go/internal/markup"
+	"hamond.dev/telegram-bot-go/internal/youtube"
+)
+
+// handleAudioCommand downloads the audio of a video without showing the
+// quality picker
+func (c *Client) handleAudioCommand(ctx context.Context, conv conversation, url string, format youtube.AudioFormat) error {
+	if !c.youtube.IsValidURL(url) {
+		return c.sendText(ctx, conv, "❌ Invalid YouTube URL. Please provide a valid YouTube or youtu.be link.")
+	}
+
+	status, err := c.newStatus(ctx, conv, "🔍 Fetching video information...")
+	if err != nil {
+		return err
+	}
+
+	videoInfo, err := c.youtube.GetVideoInfo(ctx, url)
+	if err != nil {
+		fmt.Printf("Error getting video info: %v\n", err)
+		return status.Set(ctx, "❌ Failed to get video information. Please check the URL and try again.")
+	}
+
+	selection := &formatSelection{
+		conv:      conv,
+		url:       url,
+		video:     videoInfo,
+		messageID: status.messageID,
+	}
+
+	return c.downloadAudio(ctx, selection, format)
+}
+
+// handleAudioCallback downloads the audio when the picker's audio button
+// is tapped
+func (c *Client) handleAudioCallback(ctx context.Context, query *CallbackQuery, payload string) error {
+	videoID, name, _ := strings.Cut(payload, ":")
+
+	format, ok := youtube.ParseAudioFormat(name)
+	if !ok {
+		format = youtube.AudioM4A
+	}
+
+	selection, ok := c.takeSelection(query, videoID, func(*formatSelection) bool { return true })
+	if !ok {
+		return c.answerExpired(ctx, query)
+	}
+
+	err := c.AnswerCallbackQuery(ctx, AnswerCallbackQueryRequest{
+		CallbackQueryID: query.ID,
+		Text:            "Downloading audio...",
+	})
+	if err != nil {
+		// The picker is already taken, so download anyway
+		log.Printf("Error answering audio choice: %v", err)
+	}
+
+	return c.downloadAudio(ctx, selection, format)
+}
+
+// downloadAudio extracts the audio track and sends it as a music file
+func (c *Client) downloadAudio(ctx context.Context, selection *formatSelection, format youtube.AudioFormat) error {
+	videoInfo := selection.video
+	conv := selection.conv
+	status := c.statusFor(conv.chatID, selection.messageID, "")
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	c.keepChatAction(ctx, conv, ChatActionUploadDocument)
+
+	status.SetHeader(markup.New(parseMode).
+		Text("🎵 ").Bold(videoInfo.Title).
+		Textf("\n\n⏱ Duration: %s\n📊 Audio: %s", formatDuration(videoInfo.Duration), strings.ToUpper(string(format))))
+	if err := status.Set(ctx, "⬇️ Downloading audio..."); err != nil {
+		return err
+	}
+
+	cacheKey := fileCacheKey(videoInfo.ID, "audio-"+string(format))
+	sent, err := c.sendCached(ctx, conv, cacheKey, "")
+	if err != nil {
+		return c.failCached(ctx, status, err)
+	}
+	if sent {
+		fmt.Printf("Sent cached audio for: %s (%s)\n", videoInfo.Title, format)
+		return status.Delete(ctx)
+	}
+
+	dir, err := newJobDir()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, videoInfo.ID+"_audio")
+	fmt.Printf("Starting audio download: %s (%s)\n", videoInfo.Title, format)
+	err = c.youtube.DownloadAudio(ctx, selection.url, format, name+".%(ext)s", func(p youtube.Progress) {
+		status.Progress(ctx, downloadStage(p), p.Percent())
+	})
+	if err != nil {
+		fmt.Printf("Audio download failed: %v\n", err)
+		return status.Set(ctx, "❌ Download failed. The video might be private, age-restricted or blocked in this region.")
+	}
+
+	// yt-dlp names the converted file after the target format
+	downloadedFile := name + "." + string(format)
+
+	fileInfo, err := os.Stat(downloadedFile)
+	if err == nil && fileInfo.Size() > c.UploadLimit() {
+		return status.Set(ctx, fmt.Sprintf("❌ Audio is too large (>%[1]s). Telegram bots can only send files up to %[1]s.", c.uploadLimitText()))
+	}
+
+	if err := status.Set(ctx, "📤 Uploading to Telegram..."); err != nil {
+		return err
+	}
+
+	request := SendAudioRequest{
+		ChatID:          conv.chatID,
+		MessageThreadID: conv.threadID,
+		Audio:           InputFile{Path: downloadedFile},
+		Duration:        videoInfo.Duration,
+		Performer:       videoInfo.Uploader,
+		Title:           videoInfo.Title,
+		ReplyParameters: conv.resultReply(),
+	}
+
+	thumbnailFile := name + ".jpg"
+	if err := c.youtube.DownloadThumbnail(ctx, videoInfo, thumbnailFile); err != nil {
+		fmt.Printf("Skipping thumbnail: %v\n", err)
+	} else {
+		request.Thumbnail = &InputFile{Path: thumbnailFile}
+	}
+
+	uploadCtx := WithUploadProgress(ctx, func(sent, total int64) {
+		status.Progress(ctx, "📤 Uploading to Telegram...", float64(sent)/float64(total)*100)
+	})
+	message, err := c.SendAudio(uploadCtx, request)
+	if err != nil {
+		fmt.Printf("Upload failed: %v\n", err)
+		return status.Set(ctx, "❌ Failed to upload audio to Telegram.")
+	}
+	c.rememberFile(cacheKey, message)
+
+	fmt.Printf("Audio sent successfully for: %s\n", videoInfo.Title)
+	return status.Delete(ctx)
+}
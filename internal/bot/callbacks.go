@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// callbackSeparator splits the handler prefix from its payload in callback data
+const callbackSeparator = ":"
+
+// CallbackHandlerFunc handles a button tap. payload is the callback data
+// after the prefix. Handlers must answer the query themselves, preferably
+// before starting any long-running work.
+type CallbackHandlerFunc func(ctx context.Context, query *CallbackQuery, payload string) error
+
+// HandleCallback registers a handler for callback data starting with prefix
+func (c *Client) HandleCallback(prefix string, handler CallbackHandlerFunc) {
+	c.callbacks[prefix] = handler
+}
+
+// CallbackData builds the callback data for a button handled by prefix
+func CallbackData(prefix, payload string) string {
+	return prefix + callbackSeparator + payload
+}
+
+// HandleCallbackQuery dispatches a callback query to its registered handler
+func (c *Client) HandleCallbackQuery(ctx context.Context, query *CallbackQuery) error {
+	prefix, payload, _ := strings.Cut(query.Data, callbackSeparator)
+
+	handler, ok := c.callbacks[prefix]
+	if !ok {
+		log.Printf("No callback handler for %q", query.Data)
+		return c.AnswerCallbackQuery(ctx, AnswerCallbackQueryRequest{
+			CallbackQueryID: query.ID,
+			Text:            "This button is no longer available.",
+		})
+	}
+
+	if err := handler(ctx, query, payload); err != nil {
+		return fmt.Errorf("callback %q: %w", prefix, err)
+	}
+
+	return nil
+}
+
+// registerCallbacks registers the bot's own button handlers
+func (c *Client) registerCallbacks() {
+	c.HandleCallback("help", c.handleHelpCallback)
+	c.HandleCallback("format", c.handleFormatCallback)
+	c.HandleCallback("audio", c.handleAudioCallback)
+}
+
+// handleHelpCallback sends the help text when the help button is tapped
+func (c *Client) handleHelpCallback(ctx context.Context, query *CallbackQuery, payload string) error {
+	if err := c.AnswerCallbackQuery(ctx, AnswerCallbackQueryRequest{CallbackQueryID: query.ID}); err != nil {
+		return err
+	}
+
+	if query.Message == nil {
+		return nil
+	}
+
+	return c.sendFormatted(ctx, conversationFor(query.Message), c.helpMessage())
+}
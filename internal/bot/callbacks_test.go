@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"hamond.dev/telegram-bot-go/internal/youtube"
+)
+
+func TestHandleCallbackQuery(t *testing.T) {
+	var answered []AnswerCallbackQueryRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/answerCallbackQuery") {
+			var req AnswerCallbackQueryRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			answered = append(answered, req)
+		}
+		okHandler(t, `true`)(w, r)
+	})
+
+	var gotPayload string
+	client.HandleCallback("test", func(ctx context.Context, query *CallbackQuery, payload string) error {
+		gotPayload = payload
+		return client.AnswerCallbackQuery(ctx, AnswerCallbackQueryRequest{CallbackQueryID: query.ID, Text: "ok"})
+	})
+
+	query := &CallbackQuery{ID: "1", Data: CallbackData("test", "a:b")}
+	if err := client.HandleCallbackQuery(context.Background(), query); err != nil {
+		t.Fatalf("HandleCallbackQuery() failed: %v", err)
+	}
+
+	if gotPayload != "a:b" {
+		t.Errorf("Expected payload 'a:b', got %q", gotPayload)
+	}
+
+	query = &CallbackQuery{ID: "2", Data: "missing:x"}
+	if err := client.HandleCallbackQuery(context.Background(), query); err != nil {
+		t.Fatalf("HandleCallbackQuery() failed: %v", err)
+	}
+
+	if len(answered) != 2 || answered[1].CallbackQueryID != "2" {
+		t.Errorf("Expected unknown callback to be answered, got %+v", answered)
+	}
+}
+
+func TestFormatCallbackDownloadsWhenAnswerFails(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	cache, _ := NewJSONFileCache(filepath.Join(t.TempDir(), "files.json"))
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		mu.Lock()
+		methods = append(methods, method)
+		mu.Unlock()
+		if method == "answerCallbackQuery" {
+			fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: query is too old and response timeout expired"}`)
+			return
+		}
+		okHandler(t, `{"message_id":2,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+	}, WithFileCache(cache))
+	cache.Put(fileCacheKey("abc", "18"), CachedFile{FileID: "file", Kind: FileKindVideo})
+
+	client.selections.put(&formatSelection{
+		conv:      chatConversation(1),
+		video:     &youtube.VideoInfo{ID: "abc", Title: "Video"},
+		formats:   []youtube.VideoFormat{{FormatID: "18", Quality: "360p"}},
+		messageID: 2,
+	})
+
+	query := &CallbackQuery{ID: "1", Message: &Message{MessageID: 2, Chat: Chat{ID: 1}}, Data: CallbackData("format", "abc:18")}
+	if err := client.HandleCallbackQuery(context.Background(), query); err != nil {
+		t.Fatalf("HandleCallbackQuery() failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Contains(methods, "sendVideo") {
+		t.Errorf("Expected the video to be sent despite the failed answer, got %v", methods)
+	}
+}
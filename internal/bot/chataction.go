@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// chatActionInterval repeats a chat action before Telegram hides it after
+// 5 seconds
+const chatActionInterval = 4 * time.Second
+
+// keepChatAction shows action in the conversation right away and repeats
+// it in the background until ctx is done, so long jobs don't leave the
+// chat silent. Jobs cancel ctx when they end.
+func (c *Client) keepChatAction(ctx context.Context, conv conversation, action string) {
+	request := SendChatActionRequest{ChatID: conv.chatID, MessageThreadID: conv.threadID, Action: action}
+	send := func() {
+		if err := c.SendChatAction(ctx, request); err != nil && ctx.Err() == nil {
+			log.Printf("Error sending chat action %s: %v", action, err)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.actionEvery)
+		defer ticker.Stop()
+
+		send()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				send()
+			}
+		}
+	}()
+}
@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeepChatAction(t *testing.T) {
+	requests := make(chan SendChatActionRequest, 10)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendChatAction") {
+			t.Errorf("Expected sendChatAction call, got %s", r.URL.Path)
+		}
+		var req SendChatActionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests <- req
+		okHandler(t, `true`)(w, r)
+	})
+	client.actionEvery = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client.keepChatAction(ctx, conversation{chatID: 1, threadID: 7}, ChatActionUploadVideo)
+
+	select {
+	case req := <-requests:
+		if req.ChatID != 1 || req.MessageThreadID != 7 || req.Action != ChatActionUploadVideo {
+			t.Errorf("Unexpected chat action %+v", req)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the chat action to be sent right away")
+	}
+
+	select {
+	case <-requests:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the chat action to be repeated")
+	}
+
+	cancel()
+	// A request already in flight may still arrive
+	time.Sleep(5 * client.actionEvery)
+	for len(requests) > 0 {
+		<-requests
+	}
+	select {
+	case req := <-requests:
+		t.Errorf("Expected no chat action after the job ended, got %+v", req)
+	case <-time.After(5 * client.actionEvery):
+	}
+}
+
+func TestChatActionSkipsChatRateLimit(t *testing.T) {
+	req, err := newJSONRequest(SendChatActionRequest{ChatID: -100, Action: ChatActionTyping})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.chatID != 0 {
+		t.Errorf("Expected chat actions to bypass the per-chat bucket, got chat %d", req.chatID)
+	}
+}
@@ -1,250 +1,322 @@
 package bot
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"mime/multipart"
+	"net"
 	"net/http"
-	"os"
-	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"hamond.dev/telegram-bot-go/internal/youtube"
 )
 
+const (
+	// DefaultTimeout bounds a single Bot API request
+	DefaultTimeout = 30 * time.Second
+	// DefaultUploadTimeout bounds a single file upload
+	DefaultUploadTimeout = 10 * time.Minute
+	// DefaultAPIURL is the address of the public Bot API server
+	DefaultAPIURL = "https://api.telegram.org"
+
+	// cloudUploadLimit is the largest file the public Bot API accepts
+	cloudUploadLimit = 50 * 1024 * 1024
+	// localUploadLimit is the largest file a Local Bot API server accepts
+	localUploadLimit = 2000 * 1024 * 1024
+)
+
 // Client represents the bot client
 type Client struct {
-	token   string
-	baseURL string
-	youtube *youtube.Client
+	token         string
+	apiURL        string
+	baseURL       string
+	localServer   bool
+	httpClient    *http.Client
+	timeout       time.Duration
+	uploadTimeout time.Duration
+	limiter       *RateLimiter
+	maxRetries    int
+	youtube       *youtube.Client
+	callbacks     map[string]CallbackHandlerFunc
+	commands      []*Command
+	commandIndex  map[string]*Command // By lower-case name and alias
+	me            atomic.Pointer[User]
+	selections    *selectionStore
+	inline        *inlineCache
+	files         FileCache
+	actionEvery   time.Duration // How often keepChatAction repeats an action
 }
 
-// NewClient creates a new bot client
-func NewClient(token string) *Client {
-	return &Client{
-		token:   token,
-		baseURL: "https://api.telegram.org/bot" + token,
-		youtube: youtube.NewClient(),
+// Option configures optional Client settings
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for all Bot API requests.
+// Use it to configure transport, proxy or client-wide timeouts.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
 	}
 }
 
-// GetMe returns basic information about the bot
-func (c *Client) GetMe() (*User, error) {
-	url := c.baseURL + "/getMe"
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+// WithAPIURL points the client at another Bot API server, such as a
+// self-hosted telegram-bot-api instance
+func WithAPIURL(apiURL string) Option {
+	return func(c *Client) {
+		c.apiURL = strings.TrimRight(apiURL, "/")
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+// WithLocalServer enables features of a Local Bot API server running on
+// the same machine: uploads of up to 2000MB, and files passed by their
+// local path instead of being uploaded over HTTP
+func WithLocalServer() Option {
+	return func(c *Client) {
+		c.localServer = true
 	}
+}
 
-	var response GetMeResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+// WithTimeout sets the per-request timeout for regular API calls
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
 	}
+}
 
-	if !response.Ok {
-		return nil, fmt.Errorf("API error: %s", response.Description)
+// WithUploadTimeout sets the per-request timeout for file uploads
+func WithUploadTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.uploadTimeout = timeout
 	}
-
-	return &response.Result, nil
 }
 
-// GetUpdates retrieves new updates from Telegram
-func (c *Client) GetUpdates(offset int64) ([]Update, error) {
-	url := fmt.Sprintf("%s/getUpdates?offset=%d", c.baseURL, offset)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get updates: %w", err)
+// WithRateLimiter replaces the default rate limiter; nil disables limiting
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+// WithMaxRetries sets how often a request is retried after flood control
+func WithMaxRetries(retries int) Option {
+	return func(c *Client) {
+		c.maxRetries = retries
 	}
+}
 
-	var response GetUpdatesResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+// WithFileCache enables re-sending previously uploaded content by file_id
+func WithFileCache(cache FileCache) Option {
+	return func(c *Client) {
+		c.files = cache
 	}
+}
 
-	if !response.Ok {
-		return nil, fmt.Errorf("API error getting updates")
+// NewClient creates a new bot client
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{
+		token:         token,
+		apiURL:        DefaultAPIURL,
+		httpClient:    newHTTPClient(),
+		timeout:       DefaultTimeout,
+		uploadTimeout: DefaultUploadTimeout,
+		limiter:       NewRateLimiter(DefaultRateLimits),
+		maxRetries:    DefaultMaxRetries,
+		youtube:       youtube.NewClient(),
+		callbacks:     make(map[string]CallbackHandlerFunc),
+		commandIndex:  make(map[string]*Command),
+		selections:    newSelectionStore(),
+		inline:        newInlineCache(),
+		actionEvery:   chatActionInterval,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.baseURL = c.apiURL + "/bot" + token
+	c.registerCallbacks()
+	c.registerCommands()
+
+	return c
+}
+
+// UploadLimit returns the largest file in bytes the API server accepts
+func (c *Client) UploadLimit() int64 {
+	if c.localServer {
+		return localUploadLimit
 	}
+	return cloudUploadLimit
+}
 
-	return response.Result, nil
+// newHTTPClient creates the default HTTP client shared by all requests.
+// Whole-request deadlines are applied per call through the context, so
+// only connection-level timeouts are set here.
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.DialContext = (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = 10 * time.Second
+
+	return &http.Client{Transport: transport}
 }
 
-// SendMessage sends a text message to a chat
-func (c *Client) SendMessage(chatID int64, text string) error {
-	requestBody := SendMessageRequest{
-		ChatID: chatID,
-		Text:   text,
+// GetMe returns basic information about the bot. The bot's username is
+// remembered to recognise commands addressed to it, like /help@MyBot.
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.Call(ctx, "getMe", nil, &user); err != nil {
+		return nil, err
 	}
+	c.me.Store(&user)
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
+	return &user, nil
+}
 
-	url := c.baseURL + "/sendMessage"
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+// username returns the bot's username once GetMe succeeded
+func (c *Client) username() string {
+	if me := c.me.Load(); me != nil {
+		return me.Username
 	}
-	defer resp.Body.Close()
+	return ""
+}
 
-	body, err := io.ReadAll(resp.Body)
+// GetUpdates retrieves new updates from Telegram. With a non-zero
+// Timeout the request is held open until updates arrive (long polling).
+func (c *Client) GetUpdates(ctx context.Context, request GetUpdatesRequest) ([]Update, error) {
+	req, err := newJSONRequest(request)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to marshal getUpdates request: %w", err)
 	}
+	// Leave room for the server to hold the request open
+	req.timeout = c.timeout + time.Duration(request.Timeout)*time.Second
 
-	var response SendMessageResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
+	var updates []Update
+	if err := c.call(ctx, "getUpdates", req, &updates); err != nil {
+		return nil, err
 	}
 
-	if !response.Ok {
-		return fmt.Errorf("API error sending message")
-	}
+	return updates, nil
+}
 
-	return nil
+// SendMessage sends a text message to a chat and returns the sent message
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) (*Message, error) {
+	return c.Send(ctx, SendMessageRequest{
+		ChatID: chatID,
+		Text:   text,
+	})
 }
 
-// SetWebhook sets the webhook URL for the bot
-func (c *Client) SetWebhook(webhookURL string) error {
-	requestBody := SetWebhookRequest{
-		URL: webhookURL,
+// Send sends a message with all options of SendMessageRequest
+func (c *Client) Send(ctx context.Context, request SendMessageRequest) (*Message, error) {
+	var message Message
+	if err := c.Call(ctx, "sendMessage", request, &message); err != nil {
+		return nil, err
 	}
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("failed to marshal webhook request: %w", err)
-	}
+	return &message, nil
+}
 
-	url := c.baseURL + "/setWebhook"
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to set webhook: %w", err)
+// EditMessageText replaces the text and keyboard of a sent message
+func (c *Client) EditMessageText(ctx context.Context, request EditMessageTextRequest) (*Message, error) {
+	var message Message
+	if err := c.Call(ctx, "editMessageText", request, &message); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
-	}
+	return &message, nil
+}
 
-	var response SetWebhookResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
-	}
+// DeleteMessage deletes a message
+func (c *Client) DeleteMessage(ctx context.Context, chatID, messageID int64) error {
+	return c.Call(ctx, "deleteMessage", DeleteMessageRequest{ChatID: chatID, MessageID: messageID}, nil)
+}
 
-	if !response.Ok {
-		return fmt.Errorf("API error setting webhook: %s", response.Description)
-	}
+// SendChatAction shows a chat action like "typing" in a chat
+func (c *Client) SendChatAction(ctx context.Context, request SendChatActionRequest) error {
+	return c.Call(ctx, "sendChatAction", request, nil)
+}
 
-	return nil
+// AnswerCallbackQuery acknowledges a button tap, optionally showing text
+func (c *Client) AnswerCallbackQuery(ctx context.Context, request AnswerCallbackQueryRequest) error {
+	return c.Call(ctx, "answerCallbackQuery", request, nil)
 }
 
-// DeleteWebhook removes the webhook (returns to polling mode)
-func (c *Client) DeleteWebhook() error {
-	url := c.baseURL + "/deleteWebhook"
+// AnswerInlineQuery sends the results for an inline query
+func (c *Client) AnswerInlineQuery(ctx context.Context, request AnswerInlineQueryRequest) error {
+	return c.Call(ctx, "answerInlineQuery", request, nil)
+}
 
-	resp, err := http.Post(url, "application/json", nil)
-	if err != nil {
-		return fmt.Errorf("failed to delete webhook: %w", err)
-	}
-	defer resp.Body.Close()
+// SetWebhook sets the webhook URL for the bot
+func (c *Client) SetWebhook(ctx context.Context, request SetWebhookRequest) error {
+	return c.Call(ctx, "setWebhook", request, nil)
+}
 
-	return nil
+// DeleteWebhook removes the webhook (returns to polling mode)
+func (c *Client) DeleteWebhook(ctx context.Context) error {
+	return c.Call(ctx, "deleteWebhook", nil, nil)
 }
 
 // GetWebhookInfo gets current webhook information
-func (c *Client) GetWebhookInfo() (*WebhookInfo, error) {
-	url := c.baseURL + "/getWebhookInfo"
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get webhook info: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+func (c *Client) GetWebhookInfo(ctx context.Context) (*WebhookInfo, error) {
+	var info WebhookInfo
+	if err := c.Call(ctx, "getWebhookInfo", nil, &info); err != nil {
+		return nil, err
 	}
 
-	var response GetWebhookInfoResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
-	}
-
-	if !response.Ok {
-		return nil, fmt.Errorf("API error getting webhook info")
-	}
-
-	return &response.Result, nil
+	return &info, nil
 }
 
-// SendVideo sends a video file to a chat
-func (c *Client) SendVideo(chatID int64, videoPath string) error {
-	// For now, we'll use a simple approach with sendDocument
-	// Later we can improve this to use sendVideo for better presentation
+// SetMyCommands changes the command list shown for a scope and language
+func (c *Client) SetMyCommands(ctx context.Context, request SetMyCommandsRequest) error {
+	return c.Call(ctx, "setMyCommands", request, nil)
+}
 
-	// Read the file
-	file, err := os.Open(videoPath)
-	if err != nil {
-		return fmt.Errorf("failed to open video file: %w", err)
+// GetMyCommands returns the command list for a scope and language
+func (c *Client) GetMyCommands(ctx context.Context, request GetMyCommandsRequest) ([]BotCommand, error) {
+	var commands []BotCommand
+	if err := c.Call(ctx, "getMyCommands", request, &commands); err != nil {
+		return nil, err
 	}
-	defer file.Close()
 
-	// Create multipart form data
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
+	return commands, nil
+}
 
-	// Add chat_id field
-	err = writer.WriteField("chat_id", fmt.Sprintf("%d", chatID))
-	if err != nil {
-		return fmt.Errorf("failed to create form file: %w", err)
-	}
+// DeleteMyCommands deletes the command list for a scope and language, so
+// the list of a broader scope applies again
+func (c *Client) DeleteMyCommands(ctx context.Context, request DeleteMyCommandsRequest) error {
+	return c.Call(ctx, "deleteMyCommands", request, nil)
+}
 
-	// Add document field with file
-	part, err := writer.CreateFormFile("document", filepath.Base(videoPath))
-	if err != nil {
-		return fmt.Errorf("failed to create form file: %w", err)
+// SendVideo sends a video that plays inline in the chat. Local files are
+// streamed from disk; use WithUploadProgress on ctx to follow the upload.
+func (c *Client) SendVideo(ctx context.Context, request SendVideoRequest) (*Message, error) {
+	var message Message
+	if err := c.Call(ctx, "sendVideo", request, &message); err != nil {
+		return nil, err
 	}
 
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return fmt.Errorf("failed to copy file data: %w", err)
-	}
+	return &message, nil
+}
 
-	err = writer.Close()
-	if err != nil {
-		return fmt.Errorf("failed to close writer: %w", err)
+// SendDocument sends a file as a plain attachment
+func (c *Client) SendDocument(ctx context.Context, request SendDocumentRequest) (*Message, error) {
+	var message Message
+	if err := c.Call(ctx, "sendDocument", request, &message); err != nil {
+		return nil, err
 	}
 
-	// Send the request
-	url := c.baseURL + "/sendDocument"
-	resp, err := http.Post(url, writer.FormDataContentType(), &requestBody)
-	if err != nil {
-		return fmt.Errorf("failed to send video: %w", err)
-	}
-	defer resp.Body.Close()
+	return &message, nil
+}
 
-	// Check response
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to send video, status: %d, response: %s", resp.StatusCode, string(body))
+// SendAudio sends an audio file that Telegram shows in its music player
+func (c *Client) SendAudio(ctx context.Context, request SendAudioRequest) (*Message, error) {
+	var message Message
+	if err := c.Call(ctx, "sendAudio", request, &message); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &message, nil
 }
@@ -0,0 +1,181 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestClient creates a client that talks to a fake Bot API server
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	// Rate limiting is disabled unless a test opts back in
+	opts = append([]Option{WithHTTPClient(server.Client()), WithAPIURL(server.URL), WithRateLimiter(nil)}, opts...)
+
+	return NewClient("test-token", opts...)
+}
+
+// okHandler answers every request with a successful response wrapping result
+func okHandler(t *testing.T, result string) http.HandlerFunc {
+	t.Helper()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"ok":true,"result":%s}`, result)
+	}
+}
+
+func TestGetMe(t *testing.T) {
+	var path string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		okHandler(t, `{"id":1,"is_bot":true,"first_name":"Test","username":"test_bot"}`)(w, r)
+	})
+
+	user, err := client.GetMe(context.Background())
+	if err != nil {
+		t.Fatalf("GetMe() failed: %v", err)
+	}
+
+	if path != "/bottest-token/getMe" {
+		t.Errorf("Expected path /bottest-token/getMe, got %s", path)
+	}
+
+	if user.Username != "test_bot" {
+		t.Errorf("Expected username 'test_bot', got %s", user.Username)
+	}
+}
+
+func TestRequestHonoursCancellation(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := client.GetMe(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}, WithTimeout(50*time.Millisecond))
+
+	_, err := client.GetMe(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestCallReturnsAPIError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5","parameters":{"retry_after":5,"migrate_to_chat_id":-100123}}`)
+	}, WithMaxRetries(0))
+
+	_, err := client.SendMessage(context.Background(), 1, "hello")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %v", err)
+	}
+
+	if apiErr.Method != "sendMessage" {
+		t.Errorf("Expected method sendMessage, got %s", apiErr.Method)
+	}
+
+	if apiErr.Code != 429 {
+		t.Errorf("Expected code 429, got %d", apiErr.Code)
+	}
+
+	if apiErr.RetryAfter != 5*time.Second {
+		t.Errorf("Expected RetryAfter 5s, got %s", apiErr.RetryAfter)
+	}
+
+	if apiErr.MigrateToChatID != -100123 {
+		t.Errorf("Expected MigrateToChatID -100123, got %d", apiErr.MigrateToChatID)
+	}
+}
+
+func TestCallNonJSONError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+
+	err := client.DeleteWebhook(context.Background())
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadGateway {
+		t.Errorf("Expected *APIError with code 502, got %v", err)
+	}
+}
+
+func TestCallRetriesAfterFloodControl(t *testing.T) {
+	attempts := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			fmt.Fprint(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`)
+			return
+		}
+		okHandler(t, `{"message_id":1,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+	}, WithRateLimiter(NewRateLimiter(DefaultRateLimits)))
+
+	start := time.Now()
+	if _, err := client.SendMessage(context.Background(), 1, "hello"); err != nil {
+		t.Fatalf("SendMessage() failed: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected to wait for retry_after, only waited %s", elapsed)
+	}
+}
+
+func TestCallWithoutChatUsesGlobalLimit(t *testing.T) {
+	client := newTestClient(t, okHandler(t, `true`), WithRateLimiter(NewRateLimiter(RateLimits{
+		Global:      10,
+		GlobalBurst: 1,
+		Private:     1,
+		Group:       1,
+		ChatBurst:   1,
+	})))
+
+	start := time.Now()
+	for range 2 {
+		if err := client.AnswerCallbackQuery(context.Background(), AnswerCallbackQueryRequest{CallbackQueryID: "1"}); err != nil {
+			t.Fatalf("AnswerCallbackQuery() failed: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected the second answer to wait for the global bucket, waited %s", elapsed)
+	}
+}
@@ -0,0 +1,257 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// CommandHandlerFunc handles a command whose arguments were already
+// checked against the command's spec
+type CommandHandlerFunc func(ctx context.Context, message *Message, args CommandArgs) error
+
+// Command describes a bot command
+type Command struct {
+	Name         string            // Without the leading slash, e.g. "download"
+	Aliases      []string          // Alternative names, e.g. "dl"
+	Description  string            // Shown in /help and the command menu
+	Translations map[string]string // Menu descriptions by IETF language code
+	PrivateOnly  bool              // Not offered in the menu of group chats
+	Args         []ArgSpec
+	Flags        []FlagSpec
+	Handler      CommandHandlerFunc
+}
+
+// ArgSpec describes a positional argument of a command
+type ArgSpec struct {
+	Name     string
+	Required bool
+	Choices  []string // Allowed values; empty allows any value
+}
+
+// FlagSpec describes a --flag of a command
+type FlagSpec struct {
+	Name        string
+	Description string
+}
+
+// CommandArgs holds the arguments a command was invoked with
+type CommandArgs struct {
+	Positional []string
+	Flags      map[string]string // Bare flags have the value "true"
+}
+
+// Arg returns the i-th positional argument or "" if it was not given
+func (a CommandArgs) Arg(i int) string {
+	if i < len(a.Positional) {
+		return a.Positional[i]
+	}
+	return ""
+}
+
+// Flag returns the value of a flag and whether it was given
+func (a CommandArgs) Flag(name string) (string, bool) {
+	value, ok := a.Flags[name]
+	return value, ok
+}
+
+// Usage returns how to invoke the command, e.g. "/audio <url> [format]"
+func (cmd *Command) Usage() string {
+	usage := "/" + cmd.Name
+	for _, arg := range cmd.Args {
+		name := arg.Name
+		if len(arg.Choices) > 0 {
+			name = strings.Join(arg.Choices, "|")
+		}
+
+		if arg.Required {
+			usage += " <" + name + ">"
+		} else {
+			usage += " [" + name + "]"
+		}
+	}
+	for _, flag := range cmd.Flags {
+		usage += " [--" + flag.Name + "]"
+	}
+	return usage
+}
+
+// validate checks args against the command's spec
+func (cmd *Command) validate(args CommandArgs) error {
+	for i, arg := range cmd.Args {
+		value := args.Arg(i)
+		if value == "" {
+			if arg.Required {
+				return fmt.Errorf("missing %s", arg.Name)
+			}
+			continue
+		}
+
+		if len(arg.Choices) > 0 && !slices.Contains(arg.Choices, strings.ToLower(value)) {
+			return fmt.Errorf("invalid %s %q, use one of: %s", arg.Name, value, strings.Join(arg.Choices, ", "))
+		}
+	}
+
+	if len(args.Positional) > len(cmd.Args) {
+		return fmt.Errorf("too many arguments")
+	}
+
+	for name := range args.Flags {
+		known := slices.ContainsFunc(cmd.Flags, func(flag FlagSpec) bool { return flag.Name == name })
+		if !known {
+			return fmt.Errorf("unknown flag --%s", name)
+		}
+	}
+
+	return nil
+}
+
+// HandleCommand registers a command. Registering a name or alias again
+// replaces the previous command.
+func (c *Client) HandleCommand(cmd Command) {
+	command := &cmd
+	c.commands = append(c.commands, command)
+
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		c.commandIndex[strings.ToLower(name)] = command
+	}
+}
+
+// Commands returns the registered commands in registration order
+func (c *Client) Commands() []Command {
+	commands := make([]Command, 0, len(c.commands))
+	for _, cmd := range c.commands {
+		// Skip commands whose names were all taken over by later ones
+		if c.commandIndex[strings.ToLower(cmd.Name)] == cmd {
+			commands = append(commands, *cmd)
+		}
+	}
+	return commands
+}
+
+// parseCommand splits a message like `/cmd@bot arg "quoted arg" --flag`
+// into the command name and its arguments. ok is false if text is not a
+// command or is addressed to another bot.
+func parseCommand(text, username string) (name string, args CommandArgs, ok bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", CommandArgs{}, false
+	}
+
+	// The command ends at the first space or line break
+	head, rest := text[1:], ""
+	if i := strings.IndexFunc(head, unicode.IsSpace); i >= 0 {
+		head, rest = head[:i], head[i:]
+	}
+
+	name, target, addressed := strings.Cut(head, "@")
+	if addressed && !strings.EqualFold(target, username) {
+		return "", CommandArgs{}, false
+	}
+	if name == "" {
+		return "", CommandArgs{}, false
+	}
+
+	args = CommandArgs{Flags: make(map[string]string)}
+	flagsDone := false
+	for _, token := range splitArgs(rest) {
+		switch {
+		case flagsDone || !strings.HasPrefix(token, "--"):
+			args.Positional = append(args.Positional, token)
+		case token == "--":
+			// Everything after a bare -- is positional
+			flagsDone = true
+		default:
+			flag, value, hasValue := strings.Cut(token[2:], "=")
+			if !hasValue {
+				value = "true"
+			}
+			args.Flags[strings.ToLower(flag)] = value
+		}
+	}
+
+	return strings.ToLower(name), args, true
+}
+
+// splitArgs splits text at whitespace. Single or double quotes group
+// words into one argument and a backslash escapes the next character.
+func splitArgs(text string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		escaped bool
+		started bool // Distinguishes "" from no argument
+	)
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, started = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, started = r, true
+		case unicode.IsSpace(r):
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+
+	if started {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// handleCommand parses a command message and dispatches it to the
+// registered command
+func (c *Client) handleCommand(ctx context.Context, message *Message) error {
+	name, args, ok := parseCommand(message.Text, c.username())
+	if !ok {
+		return nil // Meant for another bot
+	}
+
+	cmd, ok := c.commandIndex[name]
+	if !ok {
+		// Groups share bare commands with other bots; only answer the ones
+		// addressed to us with /command@botname
+		if message.Chat.IsGroup() && !commandAddressed(message.Text) {
+			return nil
+		}
+		return c.sendText(ctx, conversationFor(message), "❓ Unknown command. Type /help to see available commands.")
+	}
+
+	if err := cmd.validate(args); err != nil {
+		return c.sendText(ctx, conversationFor(message), fmt.Sprintf("⚠️ %s.\n\nUsage: %s\n%s", capitalize(err.Error()), cmd.Usage(), cmd.Description))
+	}
+
+	return cmd.Handler(ctx, message, args)
+}
+
+// commandAddressed reports whether a command names its bot, like
+// /start@botname
+func commandAddressed(text string) bool {
+	return strings.Contains(strings.Fields(text)[0], "@")
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
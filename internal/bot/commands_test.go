@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text       string
+		name       string
+		positional []string
+		flags      map[string]string
+		ok         bool
+	}{
+		{text: "/start", name: "start", ok: true},
+		{text: "/Download https://youtu.be/AbC", name: "download", positional: []string{"https://youtu.be/AbC"}, ok: true},
+		{text: "/download@TestBot https://youtu.be/x", name: "download", positional: []string{"https://youtu.be/x"}, ok: true},
+		{text: "/download@testbot", name: "download", ok: true},
+		{text: "/download@OtherBot https://youtu.be/x", ok: false},
+		{text: "/audio\nhttps://youtu.be/x  mp3", name: "audio", positional: []string{"https://youtu.be/x", "mp3"}, ok: true},
+		{text: `/echo "hello world" 'it''s' a\ b ""`, name: "echo", positional: []string{"hello world", "its", "a b", ""}, ok: true},
+		{text: "/download --audio --format=mp3 url -- --not-a-flag", name: "download", positional: []string{"url", "--not-a-flag"}, flags: map[string]string{"audio": "true", "format": "mp3"}, ok: true},
+		{text: "hello", ok: false},
+		{text: "/ start", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			name, args, ok := parseCommand(tt.text, "TestBot")
+			if ok != tt.ok || name != tt.name {
+				t.Fatalf("parseCommand() = %q, %v, expected %q, %v", name, ok, tt.name, tt.ok)
+			}
+			if !ok {
+				return
+			}
+
+			if !reflect.DeepEqual(args.Positional, tt.positional) {
+				t.Errorf("Expected positional %q, got %q", tt.positional, args.Positional)
+			}
+			if len(args.Flags) != len(tt.flags) || (len(tt.flags) > 0 && !reflect.DeepEqual(args.Flags, tt.flags)) {
+				t.Errorf("Expected flags %v, got %v", tt.flags, args.Flags)
+			}
+		})
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	cmd := Command{
+		Name:  "audio",
+		Args:  []ArgSpec{{Name: "url", Required: true}, {Name: "format", Choices: []string{"m4a", "mp3"}}},
+		Flags: []FlagSpec{{Name: "quiet"}},
+	}
+
+	if usage := cmd.Usage(); usage != "/audio <url> [m4a|mp3] [--quiet]" {
+		t.Errorf("Unexpected usage: %s", usage)
+	}
+
+	if err := cmd.validate(CommandArgs{Positional: []string{"url", "MP3"}}); err != nil {
+		t.Errorf("Expected valid arguments, got %v", err)
+	}
+
+	invalid := []CommandArgs{
+		{},
+		{Positional: []string{"url", "wav"}},
+		{Positional: []string{"url", "mp3", "extra"}},
+		{Positional: []string{"url"}, Flags: map[string]string{"loud": "true"}},
+	}
+	for _, args := range invalid {
+		if err := cmd.validate(args); err == nil {
+			t.Errorf("Expected %+v to be rejected", args)
+		}
+	}
+}
+
+func TestHandleCommandDispatch(t *testing.T) {
+	var texts []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		texts = append(texts, req.Text)
+		okHandler(t, `{"message_id":1,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+	})
+	client.me.Store(&User{Username: "TestBot"})
+
+	var got CommandArgs
+	client.HandleCommand(Command{
+		Name:    "echo",
+		Aliases: []string{"say"},
+		Args:    []ArgSpec{{Name: "text", Required: true}},
+		Handler: func(ctx context.Context, message *Message, args CommandArgs) error {
+			got = args
+			return nil
+		},
+	})
+
+	send := func(text string) {
+		t.Helper()
+		message := &Message{From: &User{FirstName: "Test"}, Chat: Chat{ID: 1, Type: "private"}, Text: text}
+		if err := client.handleCommand(context.Background(), message); err != nil {
+			t.Fatalf("handleCommand(%q) failed: %v", text, err)
+		}
+	}
+
+	send("/SAY@TestBot \"Hello World\"")
+	if got.Arg(0) != "Hello World" {
+		t.Errorf("Expected alias to dispatch with quoted argument, got %+v", got)
+	}
+
+	send("/echo@OtherBot hi")
+	send("/starter")
+	send("/echo")
+	if len(texts) != 2 {
+		t.Fatalf("Expected 2 replies, got %d: %q", len(texts), texts)
+	}
+	if !strings.HasPrefix(texts[0], "❓ Unknown command") {
+		t.Errorf("Expected /starter to be unknown, got %q", texts[0])
+	}
+	if !strings.Contains(texts[1], "Usage: /echo <text>") {
+		t.Errorf("Expected usage for missing argument, got %q", texts[1])
+	}
+
+	send("/help")
+	if !strings.Contains(texts[2], "/download &lt;url&gt; [--audio] - Explicitly download a video (also /dl)") ||
+		!strings.Contains(texts[2], "/echo &lt;text&gt;") {
+		t.Errorf("Expected /help to list registered commands, got %q", texts[2])
+	}
+}
+
+func TestHandleCommandInGroup(t *testing.T) {
+	var texts []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		texts = append(texts, req.Text)
+		okHandler(t, `{"message_id":1,"chat":{"id":-100,"type":"supergroup"},"date":0}`)(w, r)
+	})
+	client.me.Store(&User{Username: "TestBot"})
+
+	for _, text := range []string{"/roll", "/roll@TestBot"} {
+		message := &Message{MessageID: 1, Chat: Chat{ID: -100, Type: "supergroup"}, Text: text}
+		if err := client.handleCommand(context.Background(), message); err != nil {
+			t.Fatalf("handleCommand(%q) failed: %v", text, err)
+		}
+	}
+
+	if len(texts) != 1 || !strings.HasPrefix(texts[0], "❓ Unknown command") {
+		t.Errorf("Expected only /roll@TestBot to be answered, got %q", texts)
+	}
+}
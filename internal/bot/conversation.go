@@ -0,0 +1,62 @@
+package bot
+
+// conversation is where the bot answers a message: its chat, its forum
+// topic and the message itself, so answers can show as replies
+type conversation struct {
+	chatID    int64
+	threadID  int64 // Forum topic, 0 outside of topics
+	messageID int64 // Message that started the conversation, 0 if none
+	group     bool
+}
+
+// conversationFor returns where to answer message
+func conversationFor(message *Message) conversation {
+	conv := conversation{
+		chatID:    message.Chat.ID,
+		messageID: message.MessageID,
+		group:     message.Chat.IsGroup(),
+	}
+	if message.IsTopicMessage {
+		conv.threadID = message.MessageThreadID
+	}
+	return conv
+}
+
+// updateConversation returns where to answer update, if it happened in a
+// chat. Button taps are answered in the topic of the tapped message.
+func updateConversation(update *Update) (conversation, bool) {
+	if message := update.message(); message != nil {
+		return conversationFor(message), true
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return conversationFor(update.CallbackQuery.Message), true
+	}
+	if chat := update.Chat(); chat != nil {
+		return chatConversation(chat.ID), true
+	}
+	return conversation{}, false
+}
+
+// chatConversation answers in a chat without a topic or reply
+func chatConversation(chatID int64) conversation {
+	return conversation{chatID: chatID}
+}
+
+// replyParameters returns the reply parameters for a text answer. Several
+// people talk at once in groups, so answers there quote the trigger.
+func (c conversation) replyParameters() *ReplyParameters {
+	if !c.group {
+		return nil
+	}
+	return c.resultReply()
+}
+
+// resultReply returns the reply parameters for the file a job produced,
+// which always replies to the message that asked for it. Jobs take a while,
+// so the file is still sent if that message was deleted in the meantime.
+func (c conversation) resultReply() *ReplyParameters {
+	if c.messageID == 0 {
+		return nil
+	}
+	return &ReplyParameters{MessageID: c.messageID, AllowSendingWithoutReply: true}
+}
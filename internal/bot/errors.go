@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+)
+
+// APIError is returned when the Bot API answers a request with ok=false
+type APIError struct {
+	Method          string
+	Code            int
+	Description     string
+	RetryAfter      time.Duration // Set when the request hit flood control (429)
+	MigrateToChatID int64         // Set when a group was upgraded to a supergroup
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("telegram: %s failed with %d: %s", e.Method, e.Code, e.Description)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
+	}
+	if e.MigrateToChatID != 0 {
+		msg += fmt.Sprintf(" (migrated to chat %d)", e.MigrateToChatID)
+	}
+	return msg
+}
+
+// newAPIError builds an APIError from a failed API response
+func newAPIError(method string, response *APIResponse) *APIError {
+	err := &APIError{
+		Method:      method,
+		Code:        response.ErrorCode,
+		Description: response.Description,
+	}
+
+	if response.Parameters != nil {
+		err.RetryAfter = time.Duration(response.Parameters.RetryAfter) * time.Second
+		err.MigrateToChatID = response.Parameters.MigrateToChatID
+	}
+
+	return err
+}
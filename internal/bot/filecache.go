@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Kinds of cached files, matching the method used to send them
+const (
+	FileKindVideo    = "video"
+	FileKindAudio    = "audio"
+	FileKindDocument = "document"
+)
+
+// CachedFile is a file Telegram already stores, identified by its file_id
+type CachedFile struct {
+	FileID   string    `json:"file_id"`
+	Kind     string    `json:"kind"` // One of the FileKind constants
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// FileCache maps downloaded content to the file_id Telegram assigned to
+// it, so the same content can be re-sent without uploading it again
+type FileCache interface {
+	Get(key string) (CachedFile, bool)
+	Put(key string, file CachedFile) error
+	Delete(key string) error
+}
+
+// fileCacheKey identifies a video in a specific format
+func fileCacheKey(videoID, format string) string {
+	return videoID + ":" + format
+}
+
+// cachedFileFrom extracts the file_id of the media in a sent message
+func cachedFileFrom(message *Message) (CachedFile, bool) {
+	file := CachedFile{StoredAt: time.Now()}
+
+	switch {
+	case message == nil:
+		return file, false
+	case message.Video != nil:
+		file.FileID, file.Kind = message.Video.FileID, FileKindVideo
+	case message.Audio != nil:
+		file.FileID, file.Kind = message.Audio.FileID, FileKindAudio
+	case message.Document != nil:
+		file.FileID, file.Kind = message.Document.FileID, FileKindDocument
+	default:
+		return file, false
+	}
+
+	return file, file.FileID != ""
+}
+
+// JSONFileCache is a FileCache persisted as a single JSON file
+type JSONFileCache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]CachedFile
+}
+
+// NewJSONFileCache opens the cache stored at path, starting empty if the
+// file doesn't exist yet
+func NewJSONFileCache(path string) (*JSONFileCache, error) {
+	cache := &JSONFileCache{
+		path:    path,
+		entries: make(map[string]CachedFile),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse file cache: %w", err)
+	}
+
+	return cache, nil
+}
+
+// Get returns the cached file for key
+func (c *JSONFileCache) Get(key string) (CachedFile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, ok := c.entries[key]
+	return file, ok
+}
+
+// Put stores file under key and saves the cache
+func (c *JSONFileCache) Put(key string, file CachedFile) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = file
+	return c.save()
+}
+
+// Delete removes key, e.g. when Telegram no longer accepts its file_id
+func (c *JSONFileCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok {
+		return nil
+	}
+
+	delete(c.entries, key)
+	return c.save()
+}
+
+// save writes the cache atomically; callers must hold c.mu
+func (c *JSONFileCache) save() error {
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode file cache: %w", err)
+	}
+
+	if err := writeFileAtomic(c.path, data); err != nil {
+		return fmt.Errorf("failed to write file cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONFileCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.json")
+
+	cache, err := NewJSONFileCache(path)
+	if err != nil {
+		t.Fatalf("NewJSONFileCache() failed: %v", err)
+	}
+
+	key := fileCacheKey("dQw4w9WgXcQ", "18")
+	if err := cache.Put(key, CachedFile{FileID: "abc", Kind: FileKindVideo}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	reopened, err := NewJSONFileCache(path)
+	if err != nil {
+		t.Fatalf("Reopening cache failed: %v", err)
+	}
+
+	file, ok := reopened.Get(key)
+	if !ok || file.FileID != "abc" || file.Kind != FileKindVideo {
+		t.Errorf("Expected cached video abc, got %+v (ok=%v)", file, ok)
+	}
+
+	if err := reopened.Delete(key); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, ok := reopened.Get(key); ok {
+		t.Error("Expected entry to be deleted")
+	}
+}
+
+func TestCachedFileFrom(t *testing.T) {
+	file, ok := cachedFileFrom(&Message{Audio: &Audio{FileID: "audio-id"}})
+	if !ok || file.Kind != FileKindAudio || file.FileID != "audio-id" {
+		t.Errorf("Expected audio file, got %+v", file)
+	}
+
+	if _, ok := cachedFileFrom(&Message{Text: "hello"}); ok {
+		t.Error("Expected no file for a text message")
+	}
+}
+
+func TestSendCached(t *testing.T) {
+	var methods []string
+	var sent SendVideoRequest
+	failure := ""
+	cache, _ := NewJSONFileCache(filepath.Join(t.TempDir(), "files.json"))
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		json.NewDecoder(r.Body).Decode(&sent)
+		if failure != "" {
+			fmt.Fprint(w, failure)
+			return
+		}
+		okHandler(t, `{"message_id":1,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+	}, WithFileCache(cache))
+
+	ctx := context.Background()
+	if ok, err := client.sendCached(ctx, chatConversation(1), "missing", ""); ok || err != nil {
+		t.Errorf("Expected cache miss, got %v, %v", ok, err)
+	}
+
+	cache.Put("hit", CachedFile{FileID: "abc", Kind: FileKindVideo})
+	conv := conversationFor(&Message{MessageID: 5, Chat: Chat{ID: 1, Type: "private"}})
+	if ok, err := client.sendCached(ctx, conv, "hit", "Title"); !ok || err != nil {
+		t.Errorf("Expected cache hit, got %v, %v", ok, err)
+	}
+
+	if len(methods) != 1 || methods[0] != "sendVideo" {
+		t.Errorf("Expected a single sendVideo call, got %v", methods)
+	}
+	if sent.ReplyParameters == nil || sent.ReplyParameters.MessageID != 5 {
+		t.Errorf("Expected the video to reply to message 5, got %+v", sent.ReplyParameters)
+	}
+
+	// A blocked bot can't send an upload either, and the file_id stays valid
+	failure = `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`
+	if ok, err := client.sendCached(ctx, conv, "hit", "Title"); ok || err == nil {
+		t.Errorf("Expected the error to be returned, got %v, %v", ok, err)
+	}
+	if _, ok := cache.Get("hit"); !ok {
+		t.Error("Expected the entry to be kept")
+	}
+
+	// A rejected file_id is dropped so the file is uploaded again
+	failure = `{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`
+	if ok, err := client.sendCached(ctx, conv, "hit", "Title"); ok || err != nil {
+		t.Errorf("Expected a miss without error, got %v, %v", ok, err)
+	}
+	if _, ok := cache.Get("hit"); ok {
+		t.Error("Expected the entry to be dropped")
+	}
+}
@@ -1,135 +1,707 @@
 package bot
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+
+	"hamond.dev/telegram-bot-go/internal/markup"
+	"hamond.dev/telegram-bot-go/internal/youtube"
+)
+
+const (
+	// maxCaptionLength is the longest caption Telegram accepts for media
+	maxCaptionLength = 1024
+	// maxMessageLength is the longest text Telegram accepts for a message
+	maxMessageLength = 4096
+	// parseMode is used for all formatted messages of the bot
+	parseMode = markup.HTML
+	// maxLinksPerMessage limits the downloads one message can start
+	maxLinksPerMessage = 5
 )
 
+// HandleUpdate routes an update to the hook of its type
+func (c *Client) HandleUpdate(ctx context.Context, update *Update) error {
+	switch {
+	case update.Message != nil:
+		return c.HandleMessage(ctx, update.Message)
+	case update.EditedMessage != nil:
+		return c.HandleEditedMessage(ctx, update.EditedMessage)
+	case update.ChannelPost != nil:
+		return c.HandleChannelPost(ctx, update.ChannelPost)
+	case update.EditedChannelPost != nil:
+		return c.HandleEditedChannelPost(ctx, update.EditedChannelPost)
+	case update.InlineQuery != nil:
+		return c.HandleInlineQuery(ctx, update.InlineQuery)
+	case update.ChosenInlineResult != nil:
+		return c.HandleChosenInlineResult(ctx, update.ChosenInlineResult)
+	case update.CallbackQuery != nil:
+		return c.HandleCallbackQuery(ctx, update.CallbackQuery)
+	case update.MyChatMember != nil:
+		return c.HandleMyChatMember(ctx, update.MyChatMember)
+	default:
+		return nil // Ignore update types we don't handle
+	}
+}
+
+// HandleEditedMessage processes edits of earlier messages. Edits are
+// ignored so that fixing a typo doesn't start a download twice.
+func (c *Client) HandleEditedMessage(ctx context.Context, message *Message) error {
+	return nil
+}
+
+// HandleChannelPost processes posts in channels the bot is a member of.
+// Channels have no one to talk to, so posts are ignored.
+func (c *Client) HandleChannelPost(ctx context.Context, message *Message) error {
+	return nil
+}
+
+// HandleEditedChannelPost processes edits of channel posts; they are
+// ignored like the posts themselves
+func (c *Client) HandleEditedChannelPost(ctx context.Context, message *Message) error {
+	return nil
+}
+
 // HandleMessage processes incoming messages
-func (c *Client) HandleMessage(message *Message) error {
-	if message.Text == "" {
-		return nil // Ignore non-text messages for now
+func (c *Client) HandleMessage(ctx context.Context, message *Message) error {
+	if message.Text == "" && message.Caption == "" {
+		return nil // Ignore messages without text or caption
 	}
+	conv := conversationFor(message)
 
 	// Handle commands (messages starting with /)
 	if strings.HasPrefix(message.Text, "/") {
-		return c.handleCommand(message)
+		return c.handleCommand(ctx, message)
+	}
+
+	// Links may be anywhere in the text or the caption of a photo or
+	// forwarded post
+	links := c.youtubeLinks(message)
+
+	// Groups see every message, so only react to the ones meant for the
+	// bot: mentions, replies to the bot and links
+	text := strings.TrimSpace(message.Text)
+	if message.Chat.IsGroup() {
+		var addressed bool
+		if text, addressed = c.addressedText(message); !addressed && len(links) == 0 {
+			return nil
+		}
+	}
+
+	// Offer a quality picker for every YouTube link
+	if len(links) > 0 {
+		var errs []error
+		for _, link := range links {
+			errs = append(errs, c.handleDownloadCommand(ctx, conv, link))
+		}
+		return errors.Join(errs...)
+	}
+
+	if message.Text == "" {
+		return nil // Captions without links aren't meant for the bot
 	}
 
-	// Check if the message contains a YouTube URL
-	if c.youtube.IsValidURL(message.Text) {
-		return c.handleDownloadCommand(message.Chat.ID, strings.TrimSpace(message.Text))
+	// Check if the message picks a quality from a pending list
+	if handled, err := c.handleFormatNumber(ctx, conv, text); handled {
+		return err
 	}
 
 	// For non-YouTube URLs, provide help
-	return c.SendMessage(message.Chat.ID, "👋 Send me a YouTube link and I'll download the video for you!\n\nExample: https://youtube.com/watch?v=...\n\nOr use /help to see available commands.")
+	return c.sendText(ctx, conv, "👋 Send me a YouTube link and I'll download the video for you!\n\nExample: https://youtube.com/watch?v=...\n\nOr use /help to see available commands.")
 }
 
-// handleCommand processes bot commands
-func (c *Client) handleCommand(message *Message) error {
-	command := strings.ToLower(message.Text)
+// youtubeLinks returns the YouTube links in message, at most
+// maxLinksPerMessage of them
+func (c *Client) youtubeLinks(message *Message) []string {
+	var links []string
+	for _, link := range message.Links() {
+		if c.youtube.IsValidURL(link) && len(links) < maxLinksPerMessage {
+			links = append(links, link)
+		}
+	}
+	return links
+}
 
-	switch {
-	case strings.HasPrefix(command, "/start"):
-		welcomeText := fmt.Sprintf("Hello %s! 👋\n\nI'm your YouTube downloader bot. Just send me a YouTube link and I'll download the video for you!\n\n📹 Supported formats:\n• YouTube URLs (youtube.com/watch?v=...)\n• YouTube short URLs (youtu.be/...)\n\nThe video will be downloaded in 360p quality for optimal file size and compatibility.\n\nType /help for more info.", message.From.FirstName)
-		return c.SendMessage(message.Chat.ID, welcomeText)
-
-	case strings.HasPrefix(command, "/help"):
-		helpText := "📖 *How to use this bot:*\n\n1️⃣ Send me any YouTube link\n2️⃣ I'll download the video (360p)\n3️⃣ The video will be sent back to you\n\n*Commands:*\n/start - Welcome message\n/help - This help message\n/download <url> - Explicitly download a video\n\n*Examples:*\n• https://youtube.com/watch?v=dQw4w9WgXcQ\n• https://youtu.be/dQw4w9WgXcQ\n\n⚡ Just paste the link and I'll handle the rest!"
-		return c.SendMessage(message.Chat.ID, helpText)
-
-	case strings.HasPrefix(command, "/download "):
-		url := strings.TrimPrefix(message.Text, "/download ")
-		url = strings.TrimSpace(url)
-		if url == "" {
-			return c.SendMessage(message.Chat.ID, "Please provide a YouTube URL. Example: /download https://youtube.com/watch?v=...")
+// addressedText reports whether a group message mentions the bot or
+// replies to one of its messages, and returns the text without the mention
+func (c *Client) addressedText(message *Message) (string, bool) {
+	me := c.me.Load()
+	if me == nil {
+		return message.Text, false
+	}
+
+	reply := message.ReplyToMessage
+	repliesToBot := reply != nil && reply.From != nil && reply.From.ID == me.ID
+
+	mention := "@" + me.Username
+	var mentioned bool
+	text := message.Text
+	for _, entity := range message.Entities {
+		if entity.Type == EntityMention && strings.EqualFold(entityText(message.Text, entity), mention) {
+			mentioned = true
+			text = strings.Replace(text, entityText(message.Text, entity), "", 1)
 		}
-		return c.handleDownloadCommand(message.Chat.ID, url)
+	}
 
-	default:
-		return c.SendMessage(message.Chat.ID, "❓ Unknown command. Type /help to see available commands.")
+	return strings.TrimSpace(text), repliesToBot || mentioned
+}
+
+// registerCommands registers the bot's own commands; /help lists them in
+// this order
+func (c *Client) registerCommands() {
+	c.HandleCommand(Command{
+		Name:        "start",
+		Description: "Welcome message",
+		PrivateOnly: true,
+		Handler:     c.handleStart,
+	})
+	c.HandleCommand(Command{
+		Name:        "help",
+		Description: "This help message",
+		Handler:     c.handleHelp,
+	})
+	c.HandleCommand(Command{
+		Name:        "download",
+		Aliases:     []string{"dl"},
+		Description: "Explicitly download a video",
+		Args:        []ArgSpec{{Name: "url", Required: true}},
+		Flags:       []FlagSpec{{Name: "audio", Description: "Download the audio only"}},
+		Handler:     c.handleDownload,
+	})
+	c.HandleCommand(Command{
+		Name:        "audio",
+		Description: "Download the audio only",
+		Args: []ArgSpec{
+			{Name: "url", Required: true},
+			{Name: "format", Choices: audioFormatNames()},
+		},
+		Handler: c.handleAudio,
+	})
+}
+
+// handleStart greets the user
+func (c *Client) handleStart(ctx context.Context, message *Message, args CommandArgs) error {
+	// Messages in channels and from anonymous admins have no sender
+	name := "there"
+	if message.From != nil {
+		name = message.From.FirstName
 	}
+
+	welcome := markup.New(parseMode).
+		Text("Hello ").Bold(name).Text("! 👋\n\n").
+		Text("I'm your YouTube downloader bot. Just send me a YouTube link and I'll download the video for you!\n\n").
+		Line("📹 Supported formats:").
+		Line("• YouTube URLs (youtube.com/watch?v=...)").
+		Line("• YouTube short URLs (youtu.be/...)").
+		Text("\nYou can choose the quality (360p to 1080p) before the video is downloaded.\n\nType /help for more info.")
+	conv := conversationFor(message)
+	_, err := c.Send(ctx, SendMessageRequest{
+		ChatID:          conv.chatID,
+		MessageThreadID: conv.threadID,
+		Text:            welcome.String(),
+		ParseMode:       welcome.Mode(),
+		ReplyParameters: conv.replyParameters(),
+		ReplyMarkup: &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+			{Text: "📖 Help", CallbackData: CallbackData("help", "")},
+		}}},
+	})
+	return err
+}
+
+// handleHelp explains how to use the bot
+func (c *Client) handleHelp(ctx context.Context, message *Message, args CommandArgs) error {
+	return c.sendFormatted(ctx, conversationFor(message), c.helpMessage())
+}
+
+// handleDownload starts the quality picker, or the audio download with
+// --audio, for the given link
+func (c *Client) handleDownload(ctx context.Context, message *Message, args CommandArgs) error {
+	if _, ok := args.Flag("audio"); ok {
+		return c.handleAudioCommand(ctx, conversationFor(message), args.Arg(0), youtube.AudioM4A)
+	}
+	return c.handleDownloadCommand(ctx, conversationFor(message), args.Arg(0))
+}
+
+// handleAudio downloads the audio of a link in the requested format
+func (c *Client) handleAudio(ctx context.Context, message *Message, args CommandArgs) error {
+	format := youtube.AudioM4A
+	if name := args.Arg(1); name != "" {
+		format, _ = youtube.ParseAudioFormat(name) // Already validated
+	}
+	return c.handleAudioCommand(ctx, conversationFor(message), args.Arg(0), format)
+}
+
+// helpMessage explains how to use the bot, listing the registered commands
+func (c *Client) helpMessage() *markup.Builder {
+	help := markup.New(parseMode).
+		Text("📖 ").Bold("How to use this bot:").Text("\n\n").
+		Line("1️⃣ Send me any YouTube link").
+		Line("2️⃣ Pick a quality (360p–1080p)").
+		Line("3️⃣ The video (or just its audio) will be sent back to you").
+		Text("\n").Bold("Commands:").Text("\n")
+
+	for _, cmd := range c.Commands() {
+		help.Text(cmd.Usage() + " - " + cmd.Description)
+		if len(cmd.Aliases) > 0 {
+			help.Text(" (also /" + strings.Join(cmd.Aliases, ", /") + ")")
+		}
+		help.Text("\n")
+
+		for _, flag := range cmd.Flags {
+			help.Line("    --" + flag.Name + ": " + flag.Description)
+		}
+	}
+
+	return help.
+		Text("\n").Bold("Examples:").Text("\n").
+		Line("• https://youtube.com/watch?v=dQw4w9WgXcQ").
+		Line("• https://youtu.be/dQw4w9WgXcQ").
+		Text("\n⚡ Just paste the link and I'll handle the rest!")
+}
+
+// audioFormatNames lists the audio formats accepted by /audio
+func audioFormatNames() []string {
+	names := make([]string, len(youtube.AudioFormats))
+	for i, format := range youtube.AudioFormats {
+		names[i] = string(format)
+	}
+	return names
 }
 
 // handleDownloadCommand handles video download requests
-func (c *Client) handleDownloadCommand(chatID int64, url string) error {
+func (c *Client) handleDownloadCommand(ctx context.Context, conv conversation, url string) error {
 	// Clean the URL (remove any extra spaces or characters)
 	url = strings.TrimSpace(url)
 
 	// Validate URL
 	if !c.youtube.IsValidURL(url) {
-		return c.SendMessage(chatID, "❌ Invalid YouTube URL. Please provide a valid YouTube or youtu.be link.")
+		return c.sendText(ctx, conv, "❌ Invalid YouTube URL. Please provide a valid YouTube or youtu.be link.")
 	}
 
-	// Send "processing" message
-	err := c.SendMessage(chatID, "🔍 Fetching video information...")
+	// Show that the bot is working until the formats are offered
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	c.keepChatAction(ctx, conv, ChatActionTyping)
+
+	// Send "processing" message; it is edited in place for the rest of the job
+	status, err := c.newStatus(ctx, conv, "🔍 Fetching video information...")
 	if err != nil {
 		return err
 	}
 
 	// Get video info
-	videoInfo, err := c.youtube.GetVideoInfo(url)
+	videoInfo, err := c.youtube.GetVideoInfo(ctx, url)
 	if err != nil {
 		fmt.Printf("Error getting video info: %v\n", err)
-		return c.SendMessage(chatID, "❌ Failed to get video information. Please check the URL and try again.")
+		return status.Set(ctx, "❌ Failed to get video information. Please check the URL and try again.")
 	}
 
 	fmt.Printf("Video info: Title=%s, Duration=%d seconds\n", videoInfo.Title, videoInfo.Duration)
 
+	// Offer the formats that fit into a Telegram upload
+	var formats []youtube.VideoFormat
+	for _, format := range videoInfo.MobileFormats() {
+		if format.FileSize <= c.UploadLimit() {
+			formats = append(formats, format)
+		}
+	}
+
+	if len(formats) == 0 {
+		return status.Set(ctx, fmt.Sprintf("❌ No downloadable formats found for this video.\n\nIt might be too large (>%s) or only available in formats Telegram can't play.", c.uploadLimitText()))
+	}
+
+	c.selections.put(&formatSelection{
+		conv:      conv,
+		url:       url,
+		video:     videoInfo,
+		formats:   formats,
+		messageID: status.messageID,
+	})
+
+	return status.SetWithKeyboard(ctx, youtube.CreateFormatMessage(parseMode, videoInfo.Title, formats), formatKeyboard(videoInfo.ID, formats))
+}
+
+// formatKeyboard builds one button per offered format
+func formatKeyboard(videoID string, formats []youtube.VideoFormat) *InlineKeyboardMarkup {
+	keyboard := &InlineKeyboardMarkup{}
+
+	for _, format := range formats {
+		label := format.Quality
+		if format.FileSize > 0 {
+			label += " · " + youtube.FormatSizeToString(format.FileSize)
+		}
+
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []InlineKeyboardButton{{
+			Text:         label,
+			CallbackData: CallbackData("format", videoID+":"+format.FormatID),
+		}})
+	}
+
+	// Offer the audio track on its own as the last row
+	var audioRow []InlineKeyboardButton
+	for _, format := range youtube.AudioFormats {
+		audioRow = append(audioRow, InlineKeyboardButton{
+			Text:         "🎵 " + strings.ToUpper(string(format)),
+			CallbackData: CallbackData("audio", videoID+":"+string(format)),
+		})
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, audioRow)
+
+	return keyboard
+}
+
+// handleFormatCallback downloads the format picked from the keyboard
+func (c *Client) handleFormatCallback(ctx context.Context, query *CallbackQuery, payload string) error {
+	videoID, formatID, _ := strings.Cut(payload, ":")
+
+	selection, ok := c.takeSelection(query, videoID, func(s *formatSelection) bool {
+		_, ok := s.format(formatID)
+		return ok
+	})
+	if !ok {
+		return c.answerExpired(ctx, query)
+	}
+
+	format, _ := selection.format(formatID)
+	err := c.AnswerCallbackQuery(ctx, AnswerCallbackQueryRequest{
+		CallbackQueryID: query.ID,
+		Text:            "Downloading " + format.Quality + "...",
+	})
+	if err != nil {
+		// The picker is already taken, so download anyway
+		log.Printf("Error answering format choice: %v", err)
+	}
+
+	return c.downloadVideo(ctx, selection, format)
+}
+
+// takeSelection takes the picker that query's message belongs to
+func (c *Client) takeSelection(query *CallbackQuery, videoID string, accept func(*formatSelection) bool) (*formatSelection, bool) {
+	if query.Message == nil {
+		return nil, false
+	}
+
+	return c.selections.take(query.Message.Chat.ID, query.Message.MessageID, func(s *formatSelection) bool {
+		return s.video.ID == videoID && accept(s)
+	})
+}
+
+// answerExpired tells the user that a picker can no longer be used
+func (c *Client) answerExpired(ctx context.Context, query *CallbackQuery) error {
+	return c.AnswerCallbackQuery(ctx, AnswerCallbackQueryRequest{
+		CallbackQueryID: query.ID,
+		Text:            "This choice has expired. Please send the link again.",
+		ShowAlert:       true,
+	})
+}
+
+// handleFormatNumber downloads the format whose number was typed in reply
+// to a quality picker. It reports whether the text was such a choice.
+func (c *Client) handleFormatNumber(ctx context.Context, conv conversation, text string) (bool, error) {
+	number, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return false, nil
+	}
+
+	pending, ok := c.selections.get(conv.chatID)
+	if !ok {
+		return false, nil
+	}
+
+	selection, ok := c.selections.take(conv.chatID, pending.messageID, func(s *formatSelection) bool {
+		return number >= 1 && number <= len(s.formats)
+	})
+	if !ok {
+		return true, c.sendText(ctx, conv, fmt.Sprintf("Please choose a number between 1 and %d.", len(pending.formats)))
+	}
+
+	return true, c.downloadVideo(ctx, selection, selection.formats[number-1])
+}
+
+// downloadVideo downloads the chosen format and sends it to the chat.
+// Progress is reported by editing the quality picker message in place.
+func (c *Client) downloadVideo(ctx context.Context, selection *formatSelection, format youtube.VideoFormat) error {
+	videoInfo := selection.video
+	conv := selection.conv
+	status := c.statusFor(conv.chatID, selection.messageID, "")
+
+	// Show that a video is on its way until the job ends
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	c.keepChatAction(ctx, conv, ChatActionUploadVideo)
+
 	// Format duration nicely
 	duration := formatDuration(videoInfo.Duration)
 
-	// Send download starting message with more info
-	err = c.SendMessage(chatID, fmt.Sprintf("📹 *%s*\n\n⏱ Duration: %s\n📊 Quality: 360p\n\n⬇️ Downloading video...",
-		videoInfo.Title, duration))
+	// Show the video card above every stage
+	status.SetHeader(markup.New(parseMode).
+		Text("📹 ").Bold(videoInfo.Title).
+		Textf("\n\n⏱ Duration: %s\n📊 Quality: %s", duration, format.Quality))
+	if err := status.Set(ctx, "⬇️ Downloading video..."); err != nil {
+		return err
+	}
+
+	// Content sent before is re-sent without downloading it again
+	cacheKey := fileCacheKey(videoInfo.ID, format.FormatID)
+	sent, err := c.sendCached(ctx, conv, cacheKey, truncate(videoInfo.Title, maxCaptionLength))
+	if err != nil {
+		return c.failCached(ctx, status, err)
+	}
+	if sent {
+		fmt.Printf("Sent cached file for: %s (format %s)\n", videoInfo.Title, format.FormatID)
+		return status.Delete(ctx)
+	}
+
+	dir, err := newJobDir()
 	if err != nil {
 		return err
 	}
+	defer os.RemoveAll(dir)
 
-	// Create a simple filename
-	filename := fmt.Sprintf("%s.%%(ext)s", videoInfo.ID) // yt-dlp will replace %%(ext)s with actual extension
+	// Create a simple filename; merged format IDs contain a "+"
+	name := filepath.Join(dir, videoInfo.ID+"_"+strings.ReplaceAll(format.FormatID, "+", "_"))
+	filename := name + ".%(ext)s" // yt-dlp will replace %(ext)s with actual extension
 
 	// Download the video
-	fmt.Printf("Starting download: %s\n", videoInfo.Title)
-	err = c.youtube.DownloadFormat18(url, filename)
+	fmt.Printf("Starting download: %s (format %s)\n", videoInfo.Title, format.FormatID)
+	err = c.youtube.DownloadFormat(ctx, selection.url, format.FormatID, filename, func(p youtube.Progress) {
+		status.Progress(ctx, downloadStage(p), p.Percent())
+	})
 	if err != nil {
 		fmt.Printf("Download failed %v\n", err)
-		return c.SendMessage(chatID, "❌ Download failed. This might be due to:\n• Video is private or age-restricted\n• Video is too long\n• Regional restrictions\n\nPlease try another video.")
+		return status.Set(ctx, "❌ Download failed. This might be due to:\n• Video is private or age-restricted\n• Video is too long\n• Regional restrictions\n\nPlease try another video.")
 	}
 
-	// Find the actual downloaded file (yt-dlp replaces %%(ext)s with actual extension)
-	downloadedFile := fmt.Sprintf("%s.mp4", videoInfo.ID) // Format 18 is always mp4
+	// All offered formats are mp4, merged ones are muxed into mp4 as well
+	downloadedFile := name + ".mp4"
 	fmt.Printf("Download completed: %s -> %s\n", videoInfo.Title, downloadedFile)
 
 	// Check file size before uploading (Telegram has a 50MB limit for bots)
 	fileInfo, err := os.Stat(downloadedFile)
-	if err == nil && fileInfo.Size() > 50*1024*1024 {
-		os.Remove(downloadedFile)
-		return c.SendMessage(chatID, "❌ Video is too large (>50MB). Telegram bots can only send files up to 50MB.\n\nTry a shorter video.")
+	if err == nil && fileInfo.Size() > c.UploadLimit() {
+		return status.Set(ctx, fmt.Sprintf("❌ Video is too large (>%[1]s). Telegram bots can only send files up to %[1]s.\n\nTry a lower quality or a shorter video.", c.uploadLimitText()))
 	}
 
-	// Send upload message
-	err = c.SendMessage(chatID, "📤 Uploading to Telegram...")
-	if err != nil {
+	if err := status.Set(ctx, "📤 Uploading to Telegram..."); err != nil {
 		return err
 	}
 
 	// Send the video file back to user
 	fmt.Printf("Uploading file to Telegram: %s\n", downloadedFile)
-	err = c.SendVideo(chatID, downloadedFile)
+	uploadCtx := WithUploadProgress(ctx, func(sent, total int64) {
+		status.Progress(ctx, "📤 Uploading to Telegram...", float64(sent)/float64(total)*100)
+	})
+	request := SendVideoRequest{
+		ChatID:            conv.chatID,
+		MessageThreadID:   conv.threadID,
+		Video:             InputFile{Path: downloadedFile},
+		Duration:          videoInfo.Duration,
+		Width:             format.Width,
+		Height:            format.Height,
+		Caption:           truncate(videoInfo.Title, maxCaptionLength),
+		SupportsStreaming: true,
+		ReplyParameters:   conv.resultReply(),
+	}
+
+	// A missing thumbnail is not worth failing for; Telegram makes its own
+	thumbnailFile := name + ".jpg"
+	if err := c.youtube.DownloadThumbnail(ctx, videoInfo, thumbnailFile); err != nil {
+		fmt.Printf("Skipping thumbnail: %v\n", err)
+	} else {
+		request.Thumbnail = &InputFile{Path: thumbnailFile}
+	}
+
+	message, err := c.sendVideoOrDocument(uploadCtx, request)
 	if err != nil {
 		fmt.Printf("Upload failed: %v\n", err)
-		os.Remove(downloadedFile)
-		return c.SendMessage(chatID, "❌ Failed to upload video to Telegram. The file might be too large or in an unsupported format.")
+		return status.Set(ctx, "❌ Failed to upload video to Telegram. The file might be too large or in an unsupported format.")
 	}
+	c.rememberFile(cacheKey, message)
+	// Inline queries don't know the format, so remember the latest one too
+	c.rememberFile(fileCacheKey(videoInfo.ID, inlineVideoFormat), message)
 
-	// Clean up downloaded file
-	fmt.Printf("Cleaning up file: %s\n", downloadedFile)
-	os.Remove(downloadedFile)
-
+	// The video itself is the result, so the status message can go
 	fmt.Printf("Process completed successfully for: %s\n", videoInfo.Title)
-	return c.SendMessage(chatID, "✅ Video sent successfully! Send another link to download more videos.")
+	return status.Delete(ctx)
+}
+
+// newJobDir creates a directory of its own for the files of one download,
+// so jobs for the same video don't overwrite each other. It lives in the
+// working directory, which a Local Bot API server is set up to read.
+func newJobDir() (string, error) {
+	dir, err := os.MkdirTemp(".", "download-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	return dir, nil
+}
+
+// sendVideoOrDocument sends a playable video and falls back to a plain
+// document if Telegram rejects the file as a video
+func (c *Client) sendVideoOrDocument(ctx context.Context, request SendVideoRequest) (*Message, error) {
+	message, err := c.SendVideo(ctx, request)
+	if !isMediaError(err) {
+		return message, err
+	}
+
+	fmt.Printf("sendVideo failed, sending as document: %v\n", err)
+	return c.SendDocument(ctx, SendDocumentRequest{
+		ChatID:          request.ChatID,
+		MessageThreadID: request.MessageThreadID,
+		Document:        request.Video,
+		Thumbnail:       request.Thumbnail,
+		Caption:         request.Caption,
+		ReplyParameters: request.ReplyParameters,
+	})
+}
+
+// mediaErrorHints appear in the descriptions of errors about the sent file
+// itself, like "VIDEO_CONTENT_TYPE_INVALID" or "wrong file identifier"
+var mediaErrorHints = []string{"video", "media", "file", "content"}
+
+// isMediaError reports whether Telegram rejected a request because of its
+// media. Other failures, like a blocked bot or a missing chat, would fail
+// again for a document.
+func isMediaError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest {
+		return false
+	}
+
+	description := strings.ToLower(apiErr.Description)
+	return slices.ContainsFunc(mediaErrorHints, func(hint string) bool {
+		return strings.Contains(description, hint)
+	})
+}
+
+// sendCached re-sends the file stored under key by its file_id. It
+// reports whether a cached file was sent. Entries Telegram no longer
+// accepts are dropped so the file is uploaded again. Other API errors,
+// like a blocked bot, are returned since an upload would fail as well.
+func (c *Client) sendCached(ctx context.Context, conv conversation, key, caption string) (bool, error) {
+	if c.files == nil {
+		return false, nil
+	}
+
+	file, ok := c.files.Get(key)
+	if !ok {
+		return false, nil
+	}
+
+	input := InputFile{FileID: file.FileID}
+	reply := conv.resultReply()
+
+	var err error
+	switch file.Kind {
+	case FileKindVideo:
+		_, err = c.SendVideo(ctx, SendVideoRequest{ChatID: conv.chatID, MessageThreadID: conv.threadID, Video: input, Caption: caption, SupportsStreaming: true, ReplyParameters: reply})
+	case FileKindAudio:
+		_, err = c.SendAudio(ctx, SendAudioRequest{ChatID: conv.chatID, MessageThreadID: conv.threadID, Audio: input, Caption: caption, ReplyParameters: reply})
+	default:
+		_, err = c.SendDocument(ctx, SendDocumentRequest{ChatID: conv.chatID, MessageThreadID: conv.threadID, Document: input, Caption: caption, ReplyParameters: reply})
+	}
+
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		return true, nil
+	case isMediaError(err):
+		fmt.Printf("Cached file %s was rejected, uploading again: %v\n", key, err)
+		c.files.Delete(key)
+		return false, nil
+	case errors.As(err, &apiErr):
+		return false, err
+	default:
+		fmt.Printf("Cached file %s failed, uploading again: %v\n", key, err)
+		return false, nil
+	}
+}
+
+// failCached tells the user that a cached file couldn't be sent and
+// returns err for the error report
+func (c *Client) failCached(ctx context.Context, status *statusMessage, err error) error {
+	if statusErr := status.Set(ctx, "❌ Failed to send the file to Telegram. Please try again later."); statusErr != nil {
+		log.Printf("Error updating status: %v", statusErr)
+	}
+	return fmt.Errorf("failed to send cached file: %w", err)
+}
+
+// rememberFile stores the file_id of a sent message under key
+func (c *Client) rememberFile(key string, message *Message) {
+	if c.files == nil {
+		return
+	}
+
+	file, ok := cachedFileFrom(message)
+	if !ok {
+		return
+	}
+
+	if err := c.files.Put(key, file); err != nil {
+		fmt.Printf("Error caching file %s: %v\n", key, err)
+	}
+}
+
+// uploadLimitText formats the upload limit for messages, e.g. "50MB"
+func (c *Client) uploadLimitText() string {
+	return fmt.Sprintf("%dMB", c.UploadLimit()/1024/1024)
+}
+
+// truncate shortens text to at most limit characters
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// downloadStage describes a running download with its speed and ETA
+func downloadStage(p youtube.Progress) string {
+	var details []string
+	if p.Speed > 0 {
+		details = append(details, fmt.Sprintf("🚀 %s/s", youtube.FormatSizeToString(int64(p.Speed))))
+	}
+	if p.ETA > 0 {
+		details = append(details, fmt.Sprintf("⏳ %s left", formatDuration(int(p.ETA.Seconds()))))
+	}
+
+	stage := "⬇️ Downloading video..."
+	if len(details) > 0 {
+		stage += "\n" + strings.Join(details, " · ")
+	}
+	return stage
+}
+
+// sendText answers with a plain text message when the sent message isn't
+// needed
+func (c *Client) sendText(ctx context.Context, conv conversation, text string) error {
+	_, err := c.Send(ctx, SendMessageRequest{
+		ChatID:          conv.chatID,
+		MessageThreadID: conv.threadID,
+		Text:            text,
+		ReplyParameters: conv.replyParameters(),
+	})
+	return err
+}
+
+// sendFormatted answers with a message built with the markup package
+func (c *Client) sendFormatted(ctx context.Context, conv conversation, message *markup.Builder) error {
+	_, err := c.Send(ctx, SendMessageRequest{
+		ChatID:          conv.chatID,
+		MessageThreadID: conv.threadID,
+		Text:            message.String(),
+		ParseMode:       message.Mode(),
+		ReplyParameters: conv.replyParameters(),
+	})
+	return err
 }
 
 // formatDuration converts seconds to a human-readable format
@@ -1,15 +1,15 @@
 package bot
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
 	"testing"
 )
 
 func TestHandleCommand(t *testing.T) {
-	// Create a mock client for testing
-	client := &Client{
-		token:   "test-token",
-		baseURL: "https://api.telegram.org/bottest-token",
-	}
+	// Create a client backed by a fake Bot API server
+	client := newTestClient(t, okHandler(t, `{"message_id":1,"chat":{"id":67890,"type":"private"},"date":0}`))
 
 	tests := []struct {
 		name        string
@@ -59,16 +59,153 @@ func TestHandleCommand(t *testing.T) {
 				Text: tt.messageText,
 			}
 
-			// Note: This will fail because we can't actually send messages in tests
-			// We'll improve this with mocking in the next step
-			err := client.handleCommand(message)
+			err := client.handleCommand(context.Background(), message)
 
 			if tt.expectError && err == nil {
 				t.Error("Expected error but got nil")
 			}
-
-			// For now, we expect errors since we can't actually send messages
-			// This is just testing that the parsing logic works
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
 		})
 	}
 }
+
+func TestDecodeUpdate(t *testing.T) {
+	data := `{
+		"update_id": 7,
+		"channel_post": {
+			"message_id": 3,
+			"message_thread_id": 9,
+			"sender_chat": {"id": -100, "type": "channel", "title": "News"},
+			"chat": {"id": -100, "type": "channel", "title": "News"},
+			"date": 0,
+			"caption": "Watch https://youtu.be/x",
+			"caption_entities": [{"type": "url", "offset": 6, "length": 18}],
+			"photo": [{"file_id": "small", "file_unique_id": "s", "width": 90, "height": 90}],
+			"reply_to_message": {"message_id": 2, "chat": {"id": -100, "type": "channel"}, "date": 0, "text": "earlier"},
+			"forward_origin": {"type": "channel", "date": 0, "chat": {"id": -200, "type": "channel"}, "message_id": 5}
+		}
+	}`
+
+	var update Update
+	if err := json.Unmarshal([]byte(data), &update); err != nil {
+		t.Fatalf("Failed to decode update: %v", err)
+	}
+
+	post := update.ChannelPost
+	if post == nil || post.MessageThreadID != 9 || post.CaptionEntities[0].Type != EntityURL || len(post.Photo) != 1 {
+		t.Fatalf("Unexpected channel post: %+v", post)
+	}
+	if post.ReplyToMessage.Text != "earlier" || post.ForwardOrigin.Chat.ID != -200 {
+		t.Errorf("Expected reply and forward origin, got %+v", post)
+	}
+	if update.From() != nil || update.Chat().ID != -100 {
+		t.Errorf("Expected no sender and the channel as chat")
+	}
+}
+
+func TestHandleUpdateRouting(t *testing.T) {
+	var sent []SendMessageRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req)
+		okHandler(t, `{"message_id":1,"chat":{"id":-5,"type":"group"},"date":0}`)(w, r)
+	})
+	ctx := context.Background()
+
+	bot := User{ID: 1, IsBot: true, FirstName: "Bot"}
+	added := &Update{MyChatMember: &ChatMemberUpdated{
+		Chat:          Chat{ID: -5, Type: "group", Title: "Friends"},
+		From:          User{ID: 2, FirstName: "Alice"},
+		OldChatMember: ChatMember{Status: MemberLeft, User: bot},
+		NewChatMember: ChatMember{Status: MemberMember, User: bot},
+	}}
+	if err := client.HandleUpdate(ctx, added); err != nil {
+		t.Fatalf("HandleUpdate() failed: %v", err)
+	}
+	if len(sent) != 1 || sent[0].ChatID != -5 || sent[0].Text != groupIntroText {
+		t.Fatalf("Expected an introduction in the new group, got %+v", sent)
+	}
+
+	// Edits and channel posts must not be answered
+	ignored := []*Update{
+		{EditedMessage: &Message{Chat: Chat{ID: 5}, Text: "/help"}},
+		{ChannelPost: &Message{Chat: Chat{ID: -100, Type: "channel"}, Text: "/help"}},
+	}
+	for _, update := range ignored {
+		if err := client.HandleUpdate(ctx, update); err != nil {
+			t.Errorf("HandleUpdate() failed: %v", err)
+		}
+	}
+	if len(sent) != 1 {
+		t.Errorf("Expected no further messages, got %+v", sent[1:])
+	}
+}
+
+func TestHandleMessageInGroup(t *testing.T) {
+	var sent []SendMessageRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req)
+		okHandler(t, `{"message_id":2,"chat":{"id":-100,"type":"supergroup"},"date":0}`)(w, r)
+	})
+	client.me.Store(&User{ID: 42, IsBot: true, Username: "TestBot"})
+
+	group := Chat{ID: -100, Type: "supergroup", IsForum: true}
+	messages := []*Message{
+		{MessageID: 1, Chat: group, Text: "hello everyone"},
+		{MessageID: 2, Chat: group, Text: "@OtherBot hi", Entities: []MessageEntity{{Type: EntityMention, Offset: 0, Length: 9}}},
+		{MessageID: 3, MessageThreadID: 7, IsTopicMessage: true, Chat: group, Text: "👋 @testbot hi", Entities: []MessageEntity{{Type: EntityMention, Offset: 3, Length: 8}}},
+		{MessageID: 4, Chat: group, Text: "what now?", ReplyToMessage: &Message{MessageID: 2, From: &User{ID: 42}}},
+	}
+	for _, message := range messages {
+		if err := client.HandleMessage(context.Background(), message); err != nil {
+			t.Fatalf("HandleMessage(%q) failed: %v", message.Text, err)
+		}
+	}
+
+	if len(sent) != 2 {
+		t.Fatalf("Expected replies to the mention and the reply only, got %d", len(sent))
+	}
+	if sent[0].MessageThreadID != 7 || sent[0].ReplyParameters == nil || sent[0].ReplyParameters.MessageID != 3 {
+		t.Errorf("Expected answer in topic 7 replying to message 3, got %+v", sent[0])
+	}
+	if sent[1].MessageThreadID != 0 || sent[1].ReplyParameters == nil || sent[1].ReplyParameters.MessageID != 4 {
+		t.Errorf("Expected answer outside topics replying to message 4, got %+v", sent[1])
+	}
+}
+
+func TestEntityText(t *testing.T) {
+	text := "👋 @testbot hi"
+	if got := entityText(text, MessageEntity{Offset: 3, Length: 8}); got != "@testbot" {
+		t.Errorf("Expected @testbot, got %q", got)
+	}
+	if got := entityText(text, MessageEntity{Offset: 10, Length: 8}); got != "" {
+		t.Errorf("Expected empty text for entity out of range, got %q", got)
+	}
+}
+
+func TestIsMediaError(t *testing.T) {
+	tests := []struct {
+		err   error
+		media bool
+	}{
+		{&APIError{Code: 400, Description: "Bad Request: VIDEO_CONTENT_TYPE_INVALID"}, true},
+		{&APIError{Code: 400, Description: "Bad Request: wrong file identifier/HTTP URL specified"}, true},
+		{&APIError{Code: 400, Description: "Bad Request: chat not found"}, false},
+		{&APIError{Code: 403, Description: "Forbidden: bot was blocked by the user"}, false},
+		{&APIError{Code: 413, Description: "Request Entity Too Large"}, false},
+		{&APIError{Code: 429, Description: "Too Many Requests: retry after 5"}, false},
+		{context.Canceled, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if media := isMediaError(tt.err); media != tt.media {
+			t.Errorf("isMediaError(%v) = %v, expected %v", tt.err, media, tt.media)
+		}
+	}
+}
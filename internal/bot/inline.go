@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"hamond.dev/telegram-bot-go/internal/markup"
+	"hamond.dev/telegram-bot-go/internal/youtube"
+)
+
+const (
+	// inlineResultLimit is the number of videos offered per search
+	inlineResultLimit = 5
+	// minInlineQueryLength avoids searching for every first keystroke
+	minInlineQueryLength = 3
+	// inlineCacheTTL is how long search results are reused for a query
+	inlineCacheTTL = 10 * time.Minute
+	// inlineCacheTime is how long Telegram may cache an answer, in seconds
+	inlineCacheTime = 300
+	// inlineLookupTimeout stops yt-dlp once the user stopped waiting for
+	// the answer
+	inlineLookupTimeout = 10 * time.Second
+	// inlineVideoFormat stores the last sent video of a video ID in the
+	// file cache, whatever its format, so inline results can re-send it
+	inlineVideoFormat = "video"
+)
+
+// inlineCache remembers the videos found for recent inline queries
+type inlineCache struct {
+	mu      sync.Mutex
+	entries map[string]inlineEntry
+}
+
+// inlineEntry holds the videos found for one query
+type inlineEntry struct {
+	videos    []youtube.VideoInfo
+	expiresAt time.Time
+}
+
+// newInlineCache creates an empty inline query cache
+func newInlineCache() *inlineCache {
+	return &inlineCache{entries: make(map[string]inlineEntry)}
+}
+
+// get returns the videos found for query unless they expired
+func (c *inlineCache) get(query string) ([]youtube.VideoInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[query]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.videos, true
+}
+
+// put stores the videos found for query and drops expired entries
+func (c *inlineCache) put(query string, videos []youtube.VideoInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.entries[query] = inlineEntry{videos: videos, expiresAt: now.Add(inlineCacheTTL)}
+}
+
+// HandleInlineQuery answers "@bot query" typed in any chat with matching
+// videos. A link shows that video; any other text is searched on YouTube.
+func (c *Client) HandleInlineQuery(ctx context.Context, query *InlineQuery) error {
+	text := strings.TrimSpace(query.Query)
+
+	results := []InlineQueryResult{} // Telegram rejects a null result list
+	if utf8.RuneCountInString(text) >= minInlineQueryLength {
+		videos, err := c.inlineVideos(ctx, text)
+		if err != nil {
+			return fmt.Errorf("inline query %q: %w", text, err)
+		}
+
+		for _, video := range videos {
+			results = append(results, c.inlineResult(video))
+		}
+	}
+
+	return c.AnswerInlineQuery(ctx, AnswerInlineQueryRequest{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+	})
+}
+
+// HandleChosenInlineResult processes the inline result a user picked
+func (c *Client) HandleChosenInlineResult(ctx context.Context, result *ChosenInlineResult) error {
+	fmt.Printf("Inline result %s chosen by %s for %q\n", result.ResultID, result.From.FirstName, result.Query)
+	return nil
+}
+
+// inlineVideos finds the videos for an inline query, reusing recent results
+func (c *Client) inlineVideos(ctx context.Context, text string) ([]youtube.VideoInfo, error) {
+	key := c.inlineCacheKey(text)
+	if videos, ok := c.inline.get(key); ok {
+		return videos, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, inlineLookupTimeout)
+	defer cancel()
+
+	var videos []youtube.VideoInfo
+	if c.youtube.IsValidURL(text) {
+		video, err := c.youtube.GetVideoInfo(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		videos = []youtube.VideoInfo{*video}
+	} else {
+		var err error
+		if videos, err = c.youtube.Search(ctx, text, inlineResultLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	c.inline.put(key, videos)
+	return videos, nil
+}
+
+// inlineCacheKey returns the cache key of an inline query. Searches
+// ignore case, but the video IDs in links don't.
+func (c *Client) inlineCacheKey(text string) string {
+	if c.youtube.IsValidURL(text) {
+		return text
+	}
+	return strings.ToLower(text)
+}
+
+// inlineResult offers a video that was sent before by its file_id, and
+// any other video as a link card
+func (c *Client) inlineResult(video youtube.VideoInfo) InlineQueryResult {
+	var details []string
+	if video.Duration > 0 {
+		details = append(details, formatDuration(video.Duration))
+	}
+	if video.Uploader != "" {
+		details = append(details, video.Uploader)
+	}
+	description := strings.Join(details, " · ")
+
+	if c.files != nil {
+		if file, ok := c.files.Get(fileCacheKey(video.ID, inlineVideoFormat)); ok && file.Kind == FileKindVideo {
+			return InlineQueryResultCachedVideo{
+				ID:          video.ID,
+				VideoFileID: file.FileID,
+				Title:       video.Title,
+				Description: description,
+				Caption:     truncate(video.Title, maxCaptionLength),
+			}
+		}
+	}
+
+	card := markup.New(parseMode).Bold(video.Title).Text("\n" + video.URL)
+	result := InlineQueryResultArticle{
+		ID:    video.ID,
+		Title: video.Title,
+		InputMessageContent: InputTextMessageContent{
+			MessageText: card.String(),
+			ParseMode:   card.Mode(),
+		},
+		URL:         video.URL,
+		Description: description,
+	}
+	if thumb, ok := video.SmallThumbnail(); ok {
+		result.ThumbnailURL = thumb.URL
+	}
+
+	return result
+}
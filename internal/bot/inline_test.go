@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hamond.dev/telegram-bot-go/internal/youtube"
+)
+
+// inlineAnswer is an answerInlineQuery request as received by the server
+type inlineAnswer struct {
+	InlineQueryID string           `json:"inline_query_id"`
+	Results       []map[string]any `json:"results"` // Nil if sent as null
+	CacheTime     int              `json:"cache_time"`
+}
+
+func TestHandleInlineQuery(t *testing.T) {
+	var answers []inlineAnswer
+	cache, err := NewJSONFileCache(filepath.Join(t.TempDir(), "files.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/answerInlineQuery") {
+			t.Errorf("Unexpected call to %s", r.URL.Path)
+		}
+		var answer inlineAnswer
+		json.NewDecoder(r.Body).Decode(&answer)
+		answers = append(answers, answer)
+		okHandler(t, `true`)(w, r)
+	}, WithFileCache(cache))
+
+	// Pretend the search already ran, so yt-dlp isn't needed
+	client.inline.put("rick astley", []youtube.VideoInfo{
+		{ID: "dQw4w9WgXcQ", Title: "Never Gonna Give You Up", Duration: 212, Uploader: "Rick Astley", URL: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{ID: "yPYZpwSpKmA", Title: "Together <Forever>", URL: "https://www.youtube.com/watch?v=yPYZpwSpKmA",
+			Thumbnails: []youtube.Thumbnail{{URL: "https://i.ytimg.com/vi/yPYZpwSpKmA/default.jpg", Width: 120, Height: 90}}},
+	})
+	cache.Put(fileCacheKey("dQw4w9WgXcQ", inlineVideoFormat), CachedFile{FileID: "BAAC", Kind: FileKindVideo})
+
+	ctx := context.Background()
+	if err := client.HandleInlineQuery(ctx, &InlineQuery{ID: "q1", Query: " Rick Astley "}); err != nil {
+		t.Fatalf("HandleInlineQuery() failed: %v", err)
+	}
+
+	answer := answers[0]
+	if answer.InlineQueryID != "q1" || answer.CacheTime != inlineCacheTime || len(answer.Results) != 2 {
+		t.Fatalf("Unexpected answer: %+v", answer)
+	}
+
+	cached, card := answer.Results[0], answer.Results[1]
+	if cached["type"] != "video" || cached["video_file_id"] != "BAAC" || cached["description"] != "3 min 32 sec · Rick Astley" {
+		t.Errorf("Expected the cached video, got %v", cached)
+	}
+
+	content := card["input_message_content"].(map[string]any)
+	if card["type"] != "article" || card["thumbnail_url"] != "https://i.ytimg.com/vi/yPYZpwSpKmA/default.jpg" ||
+		content["message_text"] != "<b>Together &lt;Forever&gt;</b>\nhttps://www.youtube.com/watch?v=yPYZpwSpKmA" {
+		t.Errorf("Expected a link card, got %v", card)
+	}
+
+	// Too short queries are answered without searching
+	if err := client.HandleInlineQuery(ctx, &InlineQuery{ID: "q2", Query: "ri"}); err != nil {
+		t.Fatalf("HandleInlineQuery() failed: %v", err)
+	}
+	if len(answers) != 2 || answers[1].Results == nil || len(answers[1].Results) != 0 {
+		t.Errorf("Expected an empty answer, got %+v", answers[1:])
+	}
+}
+
+func TestInlineResultsMarshalType(t *testing.T) {
+	data, err := json.Marshal([]InlineQueryResult{
+		InlineQueryResultArticle{ID: "1", Title: "a"},
+		InlineQueryResultCachedVideo{ID: "2", VideoFileID: "f", Title: "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `{"type":"article","id":"1"`) || !strings.Contains(string(data), `{"type":"video","id":"2"`) {
+		t.Errorf("Expected result types in JSON, got %s", data)
+	}
+}
+
+func TestInlineCacheKey(t *testing.T) {
+	client := NewClient("test")
+
+	if client.inlineCacheKey("Rick Astley") != client.inlineCacheKey("rick astley") {
+		t.Error("Expected searches to ignore case")
+	}
+	if client.inlineCacheKey("https://youtu.be/AbC") == client.inlineCacheKey("https://youtu.be/abc") {
+		t.Error("Expected links to different videos to be cached apart")
+	}
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMaxJobs is the number of updates handled at the same time
+const DefaultMaxJobs = 8
+
+// jobPool runs updates in the background, at most a fixed number at a
+// time, so one long download doesn't hold up other chats
+type jobPool struct {
+	slots chan struct{}
+	jobs  sync.WaitGroup
+}
+
+// newJobPool creates a pool running up to size jobs at once
+func newJobPool(size int) *jobPool {
+	return &jobPool{slots: make(chan struct{}, max(size, 1))}
+}
+
+// acquire waits for a free slot. It fails once ctx is done, even if a slot
+// became free at the same time.
+func (p *jobPool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if err := ctx.Err(); err != nil {
+		<-p.slots
+		return err
+	}
+	return nil
+}
+
+// run starts fn on a slot taken with acquire and frees it when fn returns
+func (p *jobPool) run(fn func()) {
+	p.jobs.Add(1)
+	go func() {
+		defer func() {
+			<-p.slots
+			p.jobs.Done()
+		}()
+		fn()
+	}()
+}
+
+// wait blocks until all started jobs have returned
+func (p *jobPool) wait() {
+	p.jobs.Wait()
+}
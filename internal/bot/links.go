@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"slices"
+	"strings"
+)
+
+// Links returns the links in the message text and caption, in order and
+// without duplicates. Links come from url and text_link entities; text
+// without any is scanned for things that look like links instead.
+func (m *Message) Links() []string {
+	var links []string
+	for _, part := range []struct {
+		text     string
+		entities []MessageEntity
+	}{
+		{m.Text, m.Entities},
+		{m.Caption, m.CaptionEntities},
+	} {
+		found := entityLinks(part.text, part.entities)
+		if found == nil {
+			found = textLinks(part.text)
+		}
+
+		for _, link := range found {
+			if !slices.Contains(links, link) {
+				links = append(links, link)
+			}
+		}
+	}
+	return links
+}
+
+// entityLinks returns the links marked by entities, or nil if there are none
+func entityLinks(text string, entities []MessageEntity) []string {
+	var links []string
+	for _, entity := range entities {
+		switch entity.Type {
+		case EntityURL:
+			if link := entityText(text, entity); link != "" {
+				links = append(links, normalizeLink(link))
+			}
+		case EntityTextLink:
+			links = append(links, normalizeLink(entity.URL))
+		}
+	}
+	return links
+}
+
+// textLinks finds links in text that came without entities, e.g. from
+// clients that don't send them
+func textLinks(text string) []string {
+	var links []string
+	for _, word := range strings.Fields(text) {
+		word = strings.Trim(word, `()[]<>{}"'.,;:!?`)
+		if looksLikeLink(word) {
+			links = append(links, normalizeLink(word))
+		}
+	}
+	return links
+}
+
+// looksLikeLink reports whether word is a web address, with or without
+// scheme, like "https://youtu.be/x" or "youtube.com/watch?v=x"
+func looksLikeLink(word string) bool {
+	lower := strings.ToLower(word)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return len(word) > len("https://")
+	}
+
+	host, _, hasPath := strings.Cut(lower, "/")
+	return strings.HasPrefix(lower, "www.") || hasPath && strings.Contains(host, ".") && !strings.Contains(host, "@")
+}
+
+// normalizeLink adds the scheme Telegram leaves out of links like
+// "youtu.be/x"
+func normalizeLink(link string) string {
+	lower := strings.ToLower(link)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return link
+	}
+	return "https://" + link
+}
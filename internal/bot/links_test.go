@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMessageLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		message Message
+		links   []string
+	}{
+		{
+			name: "entities",
+			message: Message{
+				Text: "🎵 check youtu.be/a and this",
+				Entities: []MessageEntity{
+					{Type: EntityURL, Offset: 9, Length: 10},
+					{Type: EntityTextLink, Offset: 24, Length: 4, URL: "https://youtube.com/watch?v=b"},
+					{Type: EntityMention, Offset: 0, Length: 2},
+				},
+			},
+			links: []string{"https://youtu.be/a", "https://youtube.com/watch?v=b"},
+		},
+		{
+			name: "caption of a forwarded post",
+			message: Message{
+				ForwardOrigin:   &MessageOrigin{Type: "channel"},
+				Caption:         "New video: https://youtu.be/c",
+				CaptionEntities: []MessageEntity{{Type: EntityURL, Offset: 11, Length: 18}},
+			},
+			links: []string{"https://youtu.be/c"},
+		},
+		{
+			name:    "plain text fallback",
+			message: Message{Text: "two links (https://youtu.be/d, www.youtube.com/watch?v=e) and https://youtu.be/d again, mail me@example.com/x"},
+			links:   []string{"https://youtu.be/d", "https://www.youtube.com/watch?v=e"},
+		},
+		{
+			name:    "no links",
+			message: Message{Text: "hello there. how are you?"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if links := tt.message.Links(); !reflect.DeepEqual(links, tt.links) {
+				t.Errorf("Expected %q, got %q", tt.links, links)
+			}
+		})
+	}
+}
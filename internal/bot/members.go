@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+)
+
+// groupIntroText is sent when the bot is added to a group
+const groupIntroText = "👋 Thanks for adding me! Use /download <url> or send a YouTube link and I'll fetch the video."
+
+// HandleMyChatMember processes changes of the bot's own membership, such
+// as being added to a group or blocked by a user
+func (c *Client) HandleMyChatMember(ctx context.Context, update *ChatMemberUpdated) error {
+	chat := update.Chat.Title
+	if chat == "" {
+		chat = update.Chat.FirstName
+	}
+
+	wasPresent, isPresent := update.OldChatMember.IsPresent(), update.NewChatMember.IsPresent()
+	switch {
+	case !wasPresent && isPresent:
+		fmt.Printf("Added to %s chat %q by %s\n", update.Chat.Type, chat, update.From.FirstName)
+		if update.Chat.IsGroup() {
+			return c.sendText(ctx, chatConversation(update.Chat.ID), groupIntroText)
+		}
+	case wasPresent && !isPresent:
+		fmt.Printf("Removed from %s chat %q by %s\n", update.Chat.Type, chat, update.From.FirstName)
+		// Buttons of pending pickers can't be tapped anymore
+		c.selections.clear(update.Chat.ID)
+	default:
+		fmt.Printf("Status in chat %q changed to %s\n", chat, update.NewChatMember.Status)
+	}
+
+	return nil
+}
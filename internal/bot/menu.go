@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// CommandSet is the command menu for one scope and language
+type CommandSet struct {
+	Scope        BotCommandScope `json:"scope"`
+	LanguageCode string          `json:"language_code,omitempty"`
+	Commands     []BotCommand    `json:"commands"`
+}
+
+// key identifies the scope and language of the set
+func (s CommandSet) key() string {
+	return fmt.Sprintf("%s/%d/%d/%s", s.Scope.Type, s.Scope.ChatID, s.Scope.UserID, s.LanguageCode)
+}
+
+// CommandSets builds the command menus of the registered commands: one
+// for private and one for group chats, each in the default language and
+// in every language a description was translated to
+func (c *Client) CommandSets() []CommandSet {
+	commands := c.Commands()
+
+	languages := []string{""}
+	for _, cmd := range commands {
+		for language := range cmd.Translations {
+			if !slices.Contains(languages, language) {
+				languages = append(languages, language)
+			}
+		}
+	}
+	slices.Sort(languages[1:])
+
+	var sets []CommandSet
+	for _, language := range languages {
+		for _, scope := range []string{ScopeAllPrivateChats, ScopeAllGroupChats} {
+			set := CommandSet{
+				Scope:        BotCommandScope{Type: scope},
+				LanguageCode: language,
+				Commands:     []BotCommand{},
+			}
+
+			for _, cmd := range commands {
+				if cmd.PrivateOnly && scope != ScopeAllPrivateChats {
+					continue
+				}
+
+				description := cmd.Description
+				if translated, ok := cmd.Translations[language]; ok {
+					description = translated
+				}
+				set.Commands = append(set.Commands, BotCommand{Command: cmd.Name, Description: description})
+			}
+
+			sets = append(sets, set)
+		}
+	}
+
+	return sets
+}
+
+// SyncCommands pushes the command menus to Telegram if they differ from
+// the ones pushed last time, which are remembered in the file at path.
+// Menus that are no longer needed are deleted. It reports whether
+// anything was sent.
+func (c *Client) SyncCommands(ctx context.Context, path string) (bool, error) {
+	sets := c.CommandSets()
+
+	data, err := json.MarshalIndent(sets, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to encode commands: %w", err)
+	}
+
+	previous, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to read synced commands: %w", err)
+	}
+	if bytes.Equal(previous, data) {
+		return false, nil
+	}
+
+	for _, set := range sets {
+		err := c.SetMyCommands(ctx, SetMyCommandsRequest{
+			Commands:     set.Commands,
+			Scope:        &set.Scope,
+			LanguageCode: set.LanguageCode,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to set %s commands: %w", set.key(), err)
+		}
+	}
+
+	// Remove menus of scopes and languages we don't offer anymore. A
+	// corrupt file only means stale menus may be left behind.
+	var old []CommandSet
+	if len(previous) > 0 {
+		if err := json.Unmarshal(previous, &old); err != nil {
+			fmt.Printf("Ignoring unreadable synced commands: %v\n", err)
+		}
+	}
+	for _, set := range old {
+		current := slices.ContainsFunc(sets, func(s CommandSet) bool { return s.key() == set.key() })
+		if current {
+			continue
+		}
+
+		err := c.DeleteMyCommands(ctx, DeleteMyCommandsRequest{Scope: &set.Scope, LanguageCode: set.LanguageCode})
+		if err != nil {
+			return false, fmt.Errorf("failed to delete %s commands: %w", set.key(), err)
+		}
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return true, fmt.Errorf("failed to save synced commands: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandSets(t *testing.T) {
+	client := newTestClient(t, okHandler(t, `true`))
+	client.HandleCommand(Command{
+		Name:         "echo",
+		Description:  "Repeat text",
+		Translations: map[string]string{"de": "Text wiederholen"},
+		Handler:      func(context.Context, *Message, CommandArgs) error { return nil },
+	})
+
+	sets := client.CommandSets()
+	if len(sets) != 4 {
+		t.Fatalf("Expected private and group menus in 2 languages, got %d", len(sets))
+	}
+
+	private, group, german := sets[0], sets[1], sets[2]
+	if private.Scope.Type != ScopeAllPrivateChats || private.Commands[0].Command != "start" {
+		t.Errorf("Unexpected private menu: %+v", private)
+	}
+	if group.Scope.Type != ScopeAllGroupChats || len(group.Commands) != len(private.Commands)-1 {
+		t.Errorf("Expected the group menu without /start: %+v", group)
+	}
+
+	last := german.Commands[len(german.Commands)-1]
+	if german.LanguageCode != "de" || last.Description != "Text wiederholen" || german.Commands[0].Description != "Welcome message" {
+		t.Errorf("Unexpected german menu: %+v", german)
+	}
+}
+
+func TestSyncCommands(t *testing.T) {
+	var calls []string
+	var requests []SetMyCommandsRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		calls = append(calls, method)
+		if method == "setMyCommands" {
+			var req SetMyCommandsRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			requests = append(requests, req)
+		}
+		okHandler(t, `true`)(w, r)
+	})
+	path := filepath.Join(t.TempDir(), "commands.json")
+	ctx := context.Background()
+
+	updated, err := client.SyncCommands(ctx, path)
+	if err != nil || !updated {
+		t.Fatalf("SyncCommands() = %v, %v, expected an update", updated, err)
+	}
+	if len(requests) != 2 || requests[0].Scope.Type != ScopeAllPrivateChats || requests[0].Commands[0].Command != "start" {
+		t.Fatalf("Unexpected setMyCommands requests: %+v", requests)
+	}
+
+	// Nothing changed, so nothing must be sent
+	calls = nil
+	if updated, err := client.SyncCommands(ctx, path); err != nil || updated || len(calls) != 0 {
+		t.Fatalf("Expected no update, got %v, %v, %v", updated, err, calls)
+	}
+
+	// A translation adds menus; removing it again deletes them
+	client.HandleCommand(Command{
+		Name:         "echo",
+		Description:  "Repeat text",
+		Translations: map[string]string{"de": "Text wiederholen"},
+		Handler:      func(context.Context, *Message, CommandArgs) error { return nil },
+	})
+	if _, err := client.SyncCommands(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+
+	client.HandleCommand(Command{
+		Name:        "echo",
+		Description: "Repeat text",
+		Handler:     func(context.Context, *Message, CommandArgs) error { return nil },
+	})
+	calls = nil
+	if _, err := client.SyncCommands(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "setMyCommands,setMyCommands,deleteMyCommands,deleteMyCommands" {
+		t.Errorf("Expected stale menus to be deleted, got %v", calls)
+	}
+}
@@ -0,0 +1,215 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"slices"
+	"time"
+)
+
+// apologyText is sent to users when handling their update failed unexpectedly
+const apologyText = "😔 Sorry, something went wrong on our side. Please try again later."
+
+// Handler handles a single update. Client is the bot's own Handler; the
+// poller and the webhook server pass every update to one.
+type Handler interface {
+	HandleUpdate(ctx context.Context, update *Update) error
+}
+
+// HandlerFunc adapts a function to the Handler interface
+type HandlerFunc func(ctx context.Context, update *Update) error
+
+// HandleUpdate calls f(ctx, update)
+func (f HandlerFunc) HandleUpdate(ctx context.Context, update *Update) error {
+	return f(ctx, update)
+}
+
+// Middleware wraps a Handler with additional behaviour
+type Middleware func(next Handler) Handler
+
+// Chain wraps handler in middleware. The first middleware is the
+// outermost one and sees every update first.
+func Chain(handler Handler, middleware ...Middleware) Handler {
+	for _, m := range slices.Backward(middleware) {
+		handler = m(handler)
+	}
+	return handler
+}
+
+// dispatch passes an update to handler and logs a returned error. Errors
+// end here so that one failed update never stops the others.
+func dispatch(ctx context.Context, handler Handler, update *Update) {
+	if err := handler.HandleUpdate(ctx, update); err != nil {
+		log.Printf("Error handling update %d: %v", update.UpdateID, err)
+	}
+}
+
+// updateKey is the context key of the update being handled
+type updateKey struct{}
+
+// UpdateContext stores the update and the time its handling started in
+// the context, see UpdateFromContext and UpdateStartFromContext
+func UpdateContext() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *Update) error {
+			ctx = context.WithValue(ctx, updateKey{}, &updateInfo{update: update, start: time.Now()})
+			return next.HandleUpdate(ctx, update)
+		})
+	}
+}
+
+// updateInfo is the value stored by UpdateContext
+type updateInfo struct {
+	update *Update
+	start  time.Time
+}
+
+// UpdateFromContext returns the update being handled, if ctx carries one
+func UpdateFromContext(ctx context.Context) (*Update, bool) {
+	info, ok := ctx.Value(updateKey{}).(*updateInfo)
+	if !ok {
+		return nil, false
+	}
+	return info.update, true
+}
+
+// UpdateStartFromContext returns when handling of the current update started
+func UpdateStartFromContext(ctx context.Context) (time.Time, bool) {
+	info, ok := ctx.Value(updateKey{}).(*updateInfo)
+	if !ok {
+		return time.Time{}, false
+	}
+	return info.start, true
+}
+
+// Logging prints a line for every received update
+func Logging() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *Update) error {
+			fmt.Printf("Received %s\n", describeUpdate(update))
+			return next.HandleUpdate(ctx, update)
+		})
+	}
+}
+
+// Timing prints how long each update took to handle
+func Timing() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *Update) error {
+			start := time.Now()
+			err := next.HandleUpdate(ctx, update)
+			fmt.Printf("Update %d handled in %s\n", update.UpdateID, time.Since(start).Round(time.Millisecond))
+			return err
+		})
+	}
+}
+
+// PanicError is returned by Recover when a handler panicked
+type PanicError struct {
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Recover turns a panic in a handler into a *PanicError, so it can't take
+// down the poller or the webhook server. The stack trace is logged
+// together with the update ID.
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *Update) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					stack := debug.Stack()
+					log.Printf("Panic handling update %d: %v\n%s", update.UpdateID, r, stack)
+					err = &PanicError{Value: r, Stack: stack}
+				}
+			}()
+			return next.HandleUpdate(ctx, update)
+		})
+	}
+}
+
+// ReportErrors apologizes to the user when handling an update panicked and
+// sends every error to the admin chat, unless adminChatID is 0. It must
+// wrap Recover to see panics.
+func ReportErrors(client *Client, adminChatID int64) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *Update) error {
+			err := next.HandleUpdate(ctx, update)
+			if err == nil {
+				return nil
+			}
+
+			// Report even if the update was cancelled by a shutdown
+			ctx = context.WithoutCancel(ctx)
+
+			var panicErr *PanicError
+			panicked := errors.As(err, &panicErr)
+			if conv, ok := updateConversation(update); ok && panicked {
+				if sendErr := client.sendText(ctx, conv, apologyText); sendErr != nil {
+					log.Printf("Error apologizing for update %d: %v", update.UpdateID, sendErr)
+				}
+			}
+
+			if adminChatID != 0 {
+				report := fmt.Sprintf("⚠️ Error handling update %d, %s\n\n%v", update.UpdateID, describeUpdate(update), err)
+				if panicked {
+					report += "\n\n" + string(panicErr.Stack)
+				}
+				if _, sendErr := client.SendMessage(ctx, adminChatID, truncate(report, maxMessageLength)); sendErr != nil {
+					log.Printf("Error reporting update %d to admin chat: %v", update.UpdateID, sendErr)
+				}
+			}
+
+			return err
+		})
+	}
+}
+
+// AllowUsers only lets through updates sent by the given user IDs. Other
+// updates, including those without a sender, are dropped.
+func AllowUsers(userIDs ...int64) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *Update) error {
+			from := update.From()
+			if from == nil || !slices.Contains(userIDs, from.ID) {
+				fmt.Printf("Ignoring update %d from unauthorized sender\n", update.UpdateID)
+				return nil
+			}
+			return next.HandleUpdate(ctx, update)
+		})
+	}
+}
+
+// describeUpdate summarizes an update for the logs
+func describeUpdate(update *Update) string {
+	name := "unknown sender"
+	if from := update.From(); from != nil {
+		name = from.FirstName
+	}
+
+	switch {
+	case update.Message != nil:
+		return fmt.Sprintf("message from %s: %s", name, update.Message.Text)
+	case update.EditedMessage != nil:
+		return fmt.Sprintf("edited message from %s: %s", name, update.EditedMessage.Text)
+	case update.ChannelPost != nil, update.EditedChannelPost != nil:
+		return fmt.Sprintf("channel post in %s", update.Chat().Title)
+	case update.InlineQuery != nil:
+		return fmt.Sprintf("inline query from %s: %s", name, update.InlineQuery.Query)
+	case update.ChosenInlineResult != nil:
+		return fmt.Sprintf("chosen inline result from %s: %s", name, update.ChosenInlineResult.ResultID)
+	case update.CallbackQuery != nil:
+		return fmt.Sprintf("button tap from %s: %s", name, update.CallbackQuery.Data)
+	case update.MyChatMember != nil:
+		return fmt.Sprintf("membership change by %s: %s", name, update.MyChatMember.NewChatMember.Status)
+	default:
+		return fmt.Sprintf("update %d", update.UpdateID)
+	}
+}
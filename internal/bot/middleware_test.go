@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return HandlerFunc(func(ctx context.Context, update *Update) error {
+				order = append(order, name)
+				return next.HandleUpdate(ctx, update)
+			})
+		}
+	}
+
+	handler := Chain(HandlerFunc(func(ctx context.Context, update *Update) error {
+		order = append(order, "handler")
+		return nil
+	}), mark("first"), mark("second"))
+
+	handler.HandleUpdate(context.Background(), &Update{})
+
+	if strings.Join(order, ",") != "first,second,handler" {
+		t.Errorf("Unexpected order: %v", order)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	handler := Chain(HandlerFunc(func(ctx context.Context, update *Update) error {
+		var message *Message
+		_ = message.From.FirstName // nil dereference
+		return nil
+	}), Recover())
+
+	err := handler.HandleUpdate(context.Background(), &Update{UpdateID: 1})
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || !strings.Contains(string(panicErr.Stack), "TestRecover") {
+		t.Errorf("Expected panic to be returned as PanicError with stack, got %v", err)
+	}
+}
+
+func TestReportErrors(t *testing.T) {
+	var sent []SendMessageRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req)
+		okHandler(t, `{"message_id":1,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+	})
+
+	failure := errors.New("upload failed")
+	handler := Chain(HandlerFunc(func(ctx context.Context, update *Update) error {
+		if update.UpdateID == 1 {
+			panic("boom")
+		}
+		return failure
+	}), ReportErrors(client, 99), Recover())
+
+	forum := Chat{ID: 5, Type: "supergroup", IsForum: true}
+	update := &Update{UpdateID: 1, Message: &Message{MessageID: 3, MessageThreadID: 7, IsTopicMessage: true, Chat: forum, Text: "hi"}}
+	if err := handler.HandleUpdate(context.Background(), update); err == nil {
+		t.Fatal("Expected the panic to be returned")
+	}
+
+	if len(sent) != 2 || sent[0].ChatID != 5 || sent[0].Text != apologyText {
+		t.Fatalf("Expected an apology and a report, got %+v", sent)
+	}
+	if sent[0].MessageThreadID != 7 {
+		t.Errorf("Expected the apology in topic 7, got %d", sent[0].MessageThreadID)
+	}
+	if sent[1].ChatID != 99 || !strings.Contains(sent[1].Text, "update 1") || !strings.Contains(sent[1].Text, "panic: boom") {
+		t.Errorf("Unexpected admin report: %q", sent[1].Text)
+	}
+
+	// Ordinary errors are only reported to the admin
+	sent = nil
+	update = &Update{UpdateID: 2, Message: &Message{Chat: Chat{ID: 5}, Text: "hi"}}
+	if err := handler.HandleUpdate(context.Background(), update); !errors.Is(err, failure) {
+		t.Fatalf("Expected the error to be returned, got %v", err)
+	}
+	if len(sent) != 1 || sent[0].ChatID != 99 || !strings.Contains(sent[0].Text, "upload failed") {
+		t.Errorf("Expected only an admin report, got %+v", sent)
+	}
+}
+
+func TestHandleUpdateWithoutSender(t *testing.T) {
+	client := newTestClient(t, okHandler(t, `{"message_id":1,"chat":{"id":-100,"type":"channel"},"date":0}`))
+
+	// Channel posts have no sender
+	update := &Update{UpdateID: 1, Message: &Message{Chat: Chat{ID: -100, Type: "channel"}, Text: "/start"}}
+	if err := client.HandleUpdate(context.Background(), update); err != nil {
+		t.Errorf("HandleUpdate() failed: %v", err)
+	}
+
+	if describeUpdate(update) != "message from unknown sender: /start" {
+		t.Errorf("Unexpected description: %s", describeUpdate(update))
+	}
+}
+
+func TestAllowUsers(t *testing.T) {
+	handled := 0
+	handler := Chain(HandlerFunc(func(ctx context.Context, update *Update) error {
+		handled++
+		return nil
+	}), AllowUsers(1, 2))
+
+	updates := []*Update{
+		{Message: &Message{From: &User{ID: 1}}},
+		{CallbackQuery: &CallbackQuery{From: User{ID: 2}}},
+		{Message: &Message{From: &User{ID: 3}}},
+		{Message: &Message{}}, // Channel posts have no sender
+	}
+	for _, update := range updates {
+		handler.HandleUpdate(context.Background(), update)
+	}
+
+	if handled != 2 {
+		t.Errorf("Expected 2 allowed updates, got %d", handled)
+	}
+}
+
+func TestUpdateContext(t *testing.T) {
+	update := &Update{UpdateID: 42}
+
+	var got *Update
+	handler := Chain(HandlerFunc(func(ctx context.Context, update *Update) error {
+		got, _ = UpdateFromContext(ctx)
+		if _, ok := UpdateStartFromContext(ctx); !ok {
+			t.Error("Expected start time in context")
+		}
+		return nil
+	}), UpdateContext())
+
+	handler.HandleUpdate(context.Background(), update)
+
+	if got != update {
+		t.Errorf("Expected update in context, got %+v", got)
+	}
+
+	if _, ok := UpdateFromContext(context.Background()); ok {
+		t.Error("Expected no update in empty context")
+	}
+}
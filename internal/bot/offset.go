@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OffsetStore persists the polling offset across restarts
+type OffsetStore interface {
+	Load() (int64, error)
+	Save(offset int64) error
+}
+
+// FileOffsetStore keeps the offset in a small text file
+type FileOffsetStore struct {
+	path string
+}
+
+// NewFileOffsetStore creates an offset store backed by the file at path
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{path: path}
+}
+
+// Load reads the stored offset; a missing file means offset 0
+func (s *FileOffsetStore) Load() (int64, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read offset file: %w", err)
+	}
+
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse offset file: %w", err)
+	}
+
+	return offset, nil
+}
+
+// Save writes the offset atomically so a crash never leaves a torn file
+func (s *FileOffsetStore) Save(offset int64) error {
+	if err := writeFileAtomic(s.path, []byte(strconv.FormatInt(offset, 10)+"\n")); err != nil {
+		return fmt.Errorf("failed to write offset file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileOffsetStore(t *testing.T) {
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "state", "offset"))
+
+	offset, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() on missing file failed: %v", err)
+	}
+	if offset != 0 {
+		t.Errorf("Expected offset 0 for missing file, got %d", offset)
+	}
+
+	if err := store.Save(12345); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	offset, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if offset != 12345 {
+		t.Errorf("Expected offset 12345, got %d", offset)
+	}
+}
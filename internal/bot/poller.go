@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPollTimeout is the long polling timeout in seconds
+	DefaultPollTimeout = 30
+	// DefaultPollLimit is the maximum number of updates fetched per request
+	DefaultPollLimit = 100
+)
+
+// ErrWebhookActive is returned by Poller.Run when Telegram refuses
+// getUpdates because a webhook is set for the bot
+var ErrWebhookActive = errors.New("webhook is active, delete it before polling")
+
+// Poller receives updates with long polling and passes them to a handler.
+// Exported fields may be changed before calling Run.
+type Poller struct {
+	client  *Client
+	handler Handler
+
+	Timeout        int         // Long polling timeout in seconds
+	Limit          int         // Maximum updates per request (1-100)
+	AllowedUpdates []string    // Update types to receive; nil keeps the previous setting
+	Store          OffsetStore // Persists the offset across restarts; nil disables it
+	MaxJobs        int         // Updates handled at the same time; more wait for a slot
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NewPoller creates a poller with default settings
+func NewPoller(client *Client, handler Handler) *Poller {
+	return &Poller{
+		client:     client,
+		handler:    handler,
+		Timeout:    DefaultPollTimeout,
+		Limit:      DefaultPollLimit,
+		MaxJobs:    DefaultMaxJobs,
+		MinBackoff: 1 * time.Second,
+		MaxBackoff: 1 * time.Minute,
+	}
+}
+
+// Run polls for updates until ctx is cancelled. Updates are handled in
+// the background, up to MaxJobs at a time; the next batch is fetched once
+// all updates of the current one have started. It returns nil on
+// cancellation and an error if polling cannot continue, e.g. when a
+// webhook is active or another instance is polling with the same token.
+func (p *Poller) Run(ctx context.Context) error {
+	offset, err := p.loadOffset()
+	if err != nil {
+		return err
+	}
+
+	// Only updates that were handled, and all before them, are committed
+	handled := newOffsetTracker(offset)
+	pool := newJobPool(p.MaxJobs)
+	defer func() {
+		pool.wait()
+		p.saveOffset(handled.offset())
+		p.confirm(handled.offset())
+	}()
+
+	backoff := p.MinBackoff
+
+	for ctx.Err() == nil {
+		updates, err := p.client.GetUpdates(ctx, GetUpdatesRequest{
+			Offset:         offset,
+			Limit:          p.Limit,
+			Timeout:        p.Timeout,
+			AllowedUpdates: p.AllowedUpdates,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+				if strings.Contains(strings.ToLower(apiErr.Description), "webhook") {
+					return fmt.Errorf("%w: %v", ErrWebhookActive, err)
+				}
+				return fmt.Errorf("polling conflict: %w", err)
+			}
+
+			log.Printf("Error getting updates, retrying in %s: %v", backoff, err)
+			if sleepContext(ctx, backoff) != nil {
+				return nil
+			}
+			backoff = min(backoff*2, p.MaxBackoff)
+			continue
+		}
+		backoff = p.MinBackoff
+
+		if len(updates) == 0 {
+			continue
+		}
+
+		for _, update := range updates {
+			// Leave the rest of the batch to the next run on shutdown
+			if pool.acquire(ctx) != nil {
+				break
+			}
+
+			handled.start(update.UpdateID)
+			pool.run(func() {
+				dispatch(ctx, p.handler, &update)
+				handled.finish(update.UpdateID)
+			})
+			// Update offset to avoid getting the same update again
+			offset = update.UpdateID + 1
+		}
+		p.saveOffset(handled.offset())
+	}
+
+	return nil
+}
+
+// offsetTracker follows updates that are being handled in parallel and
+// computes the offset up to which all of them are done
+type offsetTracker struct {
+	mu      sync.Mutex
+	running []int64 // Started updates in order
+	done    map[int64]bool
+	next    int64 // Offset after the handled prefix
+}
+
+// newOffsetTracker starts tracking at offset
+func newOffsetTracker(offset int64) *offsetTracker {
+	return &offsetTracker{done: make(map[int64]bool), next: offset}
+}
+
+// start records that an update is being handled
+func (t *offsetTracker) start(updateID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running = append(t.running, updateID)
+}
+
+// finish records that an update was handled
+func (t *offsetTracker) finish(updateID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done[updateID] = true
+	for len(t.running) > 0 && t.done[t.running[0]] {
+		delete(t.done, t.running[0])
+		t.next = t.running[0] + 1
+		t.running = t.running[1:]
+	}
+}
+
+// offset returns the offset after the last update handled together with
+// all updates started before it
+func (t *offsetTracker) offset() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.next
+}
+
+// loadOffset returns the stored offset, or 0 without a store
+func (p *Poller) loadOffset() (int64, error) {
+	if p.Store == nil {
+		return 0, nil
+	}
+
+	offset, err := p.Store.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load polling offset: %w", err)
+	}
+
+	return offset, nil
+}
+
+// saveOffset commits the offset after a batch has been handled
+func (p *Poller) saveOffset(offset int64) {
+	if p.Store == nil {
+		return
+	}
+
+	if err := p.Store.Save(offset); err != nil {
+		log.Printf("Error saving polling offset: %v", err)
+	}
+}
+
+// confirm tells Telegram that all updates before offset were handled, so
+// they are not delivered again to the next getUpdates caller
+func (p *Poller) confirm(offset int64) {
+	if offset == 0 {
+		return
+	}
+
+	// The polling context is usually cancelled by now
+	ctx, cancel := context.WithTimeout(context.Background(), p.client.timeout)
+	defer cancel()
+
+	_, err := p.client.GetUpdates(ctx, GetUpdatesRequest{Offset: offset, Limit: 1})
+	if err != nil {
+		log.Printf("Error confirming polling offset %d: %v", offset, err)
+	}
+}
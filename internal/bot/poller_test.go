@@ -0,0 +1,226 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPollerLongPolling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var requests []GetUpdatesRequest
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req GetUpdatesRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		if req.Offset == 0 {
+			okHandler(t, `[{"update_id":41},{"update_id":42}]`)(w, r)
+			return
+		}
+		okHandler(t, `[]`)(w, r)
+	})
+
+	var handled []int64
+	poller := NewPoller(client, HandlerFunc(func(ctx context.Context, update *Update) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, update.UpdateID)
+		if len(handled) == 2 {
+			cancel()
+		}
+		return nil
+	}))
+	poller.AllowedUpdates = []string{"message"}
+
+	if err := poller.Run(ctx); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if len(handled) != 2 {
+		t.Fatalf("Expected 2 handled updates, got %d", len(handled))
+	}
+
+	first := requests[0]
+	if first.Timeout != DefaultPollTimeout || first.Limit != DefaultPollLimit {
+		t.Errorf("Expected timeout %d and limit %d, got %d and %d",
+			DefaultPollTimeout, DefaultPollLimit, first.Timeout, first.Limit)
+	}
+
+	if len(first.AllowedUpdates) != 1 || first.AllowedUpdates[0] != "message" {
+		t.Errorf("Expected allowed_updates [message], got %v", first.AllowedUpdates)
+	}
+}
+
+func TestPollerWebhookConflict(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"ok":false,"error_code":409,"description":"Conflict: can't use getUpdates method while webhook is active; use deleteWebhook to delete the webhook first"}`)
+	})
+
+	poller := NewPoller(client, HandlerFunc(func(ctx context.Context, update *Update) error { return nil }))
+
+	err := poller.Run(context.Background())
+	if !errors.Is(err, ErrWebhookActive) {
+		t.Errorf("Expected ErrWebhookActive, got %v", err)
+	}
+}
+
+func TestPollerBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var times []time.Time
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		if len(times) == 4 {
+			cancel()
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+
+	poller := NewPoller(client, HandlerFunc(func(ctx context.Context, update *Update) error { return nil }))
+	poller.MinBackoff = 10 * time.Millisecond
+	poller.MaxBackoff = 40 * time.Millisecond
+
+	if err := poller.Run(ctx); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	// Delays should be roughly 10ms, 20ms, 40ms
+	if gap := times[3].Sub(times[2]); gap < 40*time.Millisecond {
+		t.Errorf("Expected backoff to grow to 40ms, got %s", gap)
+	}
+}
+
+func TestPollerPersistsAndConfirmsOffset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var offsets []int64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req GetUpdatesRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		offsets = append(offsets, req.Offset)
+		mu.Unlock()
+
+		if req.Offset == 10 {
+			okHandler(t, `[{"update_id":10},{"update_id":11},{"update_id":12}]`)(w, r)
+			return
+		}
+		okHandler(t, `[]`)(w, r)
+	})
+
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+	store.Save(10)
+
+	// Shutting down during the first update must not skip the others. One
+	// job at a time keeps the rest of the batch from starting meanwhile.
+	var handled []int64
+	poller := NewPoller(client, HandlerFunc(func(ctx context.Context, update *Update) error {
+		handled = append(handled, update.UpdateID)
+		if update.UpdateID == 10 {
+			cancel()
+		}
+		return nil
+	}))
+	poller.Store = store
+	poller.MaxJobs = 1
+
+	if err := poller.Run(ctx); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if offsets[0] != 10 {
+		t.Errorf("Expected polling to resume from stored offset 10, got %d", offsets[0])
+	}
+
+	if !slices.Equal(handled, []int64{10}) {
+		t.Errorf("Expected only update 10 to be handled, got %v", handled)
+	}
+
+	if last := offsets[len(offsets)-1]; last != 11 {
+		t.Errorf("Expected offset 11 to be confirmed on shutdown, got %d", last)
+	}
+
+	if saved, _ := store.Load(); saved != 11 {
+		t.Errorf("Expected stored offset 11, got %d", saved)
+	}
+}
+
+func TestPollerHandlesUpdatesConcurrently(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var offsets []int64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req GetUpdatesRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		offsets = append(offsets, req.Offset)
+		mu.Unlock()
+
+		switch req.Offset {
+		case 0:
+			okHandler(t, `[{"update_id":1}]`)(w, r)
+		case 2:
+			okHandler(t, `[{"update_id":2}]`)(w, r)
+		default:
+			okHandler(t, `[]`)(w, r)
+		}
+	})
+
+	// Update 1 is a long download that must not hold up update 2
+	second := make(chan struct{})
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+	poller := NewPoller(client, HandlerFunc(func(ctx context.Context, update *Update) error {
+		switch update.UpdateID {
+		case 1:
+			select {
+			case <-second:
+			case <-time.After(time.Second):
+				t.Error("Expected update 2 to be handled while update 1 runs")
+			}
+			cancel()
+		case 2:
+			// Update 1 is still running, so nothing may be committed yet
+			if saved, _ := store.Load(); saved != 0 {
+				t.Errorf("Expected no stored offset while update 1 runs, got %d", saved)
+			}
+			close(second)
+		}
+		return nil
+	}))
+	poller.Store = store
+
+	if err := poller.Run(ctx); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if saved, _ := store.Load(); saved != 3 {
+		t.Errorf("Expected stored offset 3 once both updates were handled, got %d", saved)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if last := offsets[len(offsets)-1]; last != 3 {
+		t.Errorf("Expected offset 3 to be confirmed on shutdown, got %d", last)
+	}
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimits configures the outgoing request limits.
+// Rates are in requests per second.
+type RateLimits struct {
+	Global      float64
+	GlobalBurst int
+	Private     float64
+	Group       float64
+	ChatBurst   int
+}
+
+// DefaultRateLimits follows the limits documented in the Bot FAQ:
+// about 30 messages per second overall, one message per second in a
+// single chat and 20 messages per minute in a group.
+var DefaultRateLimits = RateLimits{
+	Global:      30,
+	GlobalBurst: 30,
+	Private:     1,
+	Group:       20.0 / 60.0,
+	ChatBurst:   1,
+}
+
+// chatBucketIdle is how long an unused per-chat bucket is kept around
+const chatBucketIdle = 5 * time.Minute
+
+// tokenBucket is a token bucket that hands out reservations.
+// Tokens may go negative, which queues callers in arrival order.
+type tokenBucket struct {
+	rate   float64 // Tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full bucket
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+// refill adds the tokens accumulated since the last call
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// reserve takes one token and returns how long the caller must wait for it
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// pause makes the bucket unavailable for at least d
+func (b *tokenBucket) pause(now time.Time, d time.Duration) {
+	b.refill(now)
+	b.tokens = min(b.tokens, 1) - d.Seconds()*b.rate
+}
+
+// RateLimiter spaces out outgoing requests using a global token bucket
+// and one bucket per chat.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limits    RateLimits
+	global    *tokenBucket
+	chats     map[int64]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter with the given limits
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	now := time.Now()
+
+	return &RateLimiter{
+		limits:    limits,
+		global:    newTokenBucket(limits.Global, limits.GlobalBurst, now),
+		chats:     make(map[int64]*tokenBucket),
+		lastSweep: now,
+		now:       time.Now,
+	}
+}
+
+// Wait blocks until a request to chatID may be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, chatID int64) error {
+	delay := l.reserve(chatID)
+	if delay <= 0 {
+		return nil
+	}
+
+	return sleepContext(ctx, delay)
+}
+
+// Pause blocks further requests to chatID for d, e.g. after a 429.
+// A zero chatID pauses all requests.
+func (l *RateLimiter) Pause(chatID int64, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if chatID == 0 {
+		l.global.pause(now, d)
+		return
+	}
+	l.chatBucket(chatID, now).pause(now, d)
+}
+
+// reserve takes a token from the global and chat buckets and returns
+// the longest of the two waits
+func (l *RateLimiter) reserve(chatID int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	delay := l.global.reserve(now)
+	if chatID != 0 {
+		delay = max(delay, l.chatBucket(chatID, now).reserve(now))
+	}
+
+	return delay
+}
+
+// chatBucket returns the bucket for chatID, creating it if needed.
+// Negative IDs are groups, supergroups and channels.
+func (l *RateLimiter) chatBucket(chatID int64, now time.Time) *tokenBucket {
+	bucket, ok := l.chats[chatID]
+	if !ok {
+		rate := l.limits.Private
+		if chatID < 0 {
+			rate = l.limits.Group
+		}
+		bucket = newTokenBucket(rate, l.limits.ChatBurst, now)
+		l.chats[chatID] = bucket
+	}
+
+	return bucket
+}
+
+// sweep drops chat buckets that have been idle and are full again
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < chatBucketIdle {
+		return
+	}
+	l.lastSweep = now
+
+	for chatID, bucket := range l.chats {
+		if now.Sub(bucket.last) < chatBucketIdle {
+			continue
+		}
+		bucket.refill(now)
+		if bucket.tokens >= bucket.burst {
+			delete(l.chats, chatID)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestRateLimiter creates a limiter driven by a fake clock
+func newTestRateLimiter(limits RateLimits) (*RateLimiter, *time.Time) {
+	now := time.Unix(0, 0)
+	limiter := NewRateLimiter(limits)
+	limiter.global = newTokenBucket(limits.Global, limits.GlobalBurst, now)
+	limiter.lastSweep = now
+	limiter.now = func() time.Time { return now }
+
+	return limiter, &now
+}
+
+func TestRateLimiterPerChat(t *testing.T) {
+	limiter, _ := newTestRateLimiter(DefaultRateLimits)
+
+	if delay := limiter.reserve(1); delay != 0 {
+		t.Errorf("First message should not wait, got %s", delay)
+	}
+
+	if delay := limiter.reserve(1); delay != time.Second {
+		t.Errorf("Second private message should wait 1s, got %s", delay)
+	}
+
+	if delay := limiter.reserve(2); delay != 0 {
+		t.Errorf("Other chats should not wait, got %s", delay)
+	}
+
+	limiter.reserve(-100)
+	if delay := limiter.reserve(-100); delay != 3*time.Second {
+		t.Errorf("Second group message should wait 3s, got %s", delay)
+	}
+}
+
+func TestRateLimiterGlobal(t *testing.T) {
+	limiter, now := newTestRateLimiter(RateLimits{
+		Global:      2,
+		GlobalBurst: 2,
+		Private:     100,
+		Group:       100,
+		ChatBurst:   100,
+	})
+
+	limiter.reserve(1)
+	limiter.reserve(2)
+	if delay := limiter.reserve(3); delay != 500*time.Millisecond {
+		t.Errorf("Expected global wait of 500ms, got %s", delay)
+	}
+
+	*now = now.Add(2 * time.Second)
+	if delay := limiter.reserve(4); delay != 0 {
+		t.Errorf("Expected bucket to refill, got wait %s", delay)
+	}
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	limiter, now := newTestRateLimiter(DefaultRateLimits)
+
+	limiter.Pause(1, 5*time.Second)
+	if delay := limiter.reserve(1); delay != 5*time.Second {
+		t.Errorf("Expected paused chat to wait 5s, got %s", delay)
+	}
+
+	*now = now.Add(10 * time.Minute)
+	limiter.reserve(2)
+	if _, ok := limiter.chats[1]; ok {
+		t.Error("Expected idle chat bucket to be swept")
+	}
+}
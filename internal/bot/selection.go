@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"hamond.dev/telegram-bot-go/internal/youtube"
+)
+
+// selectionTTL is how long a quality picker stays valid
+const selectionTTL = 10 * time.Minute
+
+// formatSelection is a quality picker waiting for the user's choice
+type formatSelection struct {
+	conv      conversation // Where the request was made
+	url       string
+	video     *youtube.VideoInfo
+	formats   []youtube.VideoFormat
+	messageID int64 // The picker message, reused as status message
+	expiresAt time.Time
+}
+
+// format returns the offered format with the given ID
+func (s *formatSelection) format(formatID string) (youtube.VideoFormat, bool) {
+	index := slices.IndexFunc(s.formats, func(f youtube.VideoFormat) bool {
+		return f.FormatID == formatID
+	})
+	if index < 0 {
+		return youtube.VideoFormat{}, false
+	}
+	return s.formats[index], true
+}
+
+// selectionKey identifies a picker by its chat and message
+type selectionKey struct {
+	chatID    int64
+	messageID int64
+}
+
+// selectionStore tracks the pending quality pickers of each chat. A chat
+// can have several, one for every link it sent; typed numbers choose from
+// the newest one.
+type selectionStore struct {
+	mu         sync.Mutex
+	selections map[selectionKey]*formatSelection
+	latest     map[int64]int64 // Newest picker message of each chat
+	now        func() time.Time
+}
+
+// newSelectionStore creates an empty selection store
+func newSelectionStore() *selectionStore {
+	return &selectionStore{
+		selections: make(map[selectionKey]*formatSelection),
+		latest:     make(map[int64]int64),
+		now:        time.Now,
+	}
+}
+
+// put stores a picker under its chat and message
+func (s *selectionStore) put(selection *formatSelection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	selection.expiresAt = s.now().Add(selectionTTL)
+	s.selections[selectionKey{selection.conv.chatID, selection.messageID}] = selection
+	s.latest[selection.conv.chatID] = selection.messageID
+
+	// Drop expired pickers while we hold the lock
+	for key, other := range s.selections {
+		if s.now().After(other.expiresAt) {
+			delete(s.selections, key)
+		}
+	}
+	for chatID, messageID := range s.latest {
+		if _, ok := s.selections[selectionKey{chatID, messageID}]; !ok {
+			delete(s.latest, chatID)
+		}
+	}
+}
+
+// get returns the chat's newest picker if it has not expired
+func (s *selectionStore) get(chatID int64) (*formatSelection, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messageID, ok := s.latest[chatID]
+	if !ok {
+		return nil, false
+	}
+
+	key := selectionKey{chatID, messageID}
+	selection, ok := s.selections[key]
+	if !ok || s.now().After(selection.expiresAt) {
+		delete(s.selections, key)
+		delete(s.latest, chatID)
+		return nil, false
+	}
+
+	return selection, true
+}
+
+// take removes the picker in the given message and returns it if accept
+// approves the choice. Taking the picker in one step keeps a double tap
+// from starting two downloads.
+func (s *selectionStore) take(chatID, messageID int64, accept func(*formatSelection) bool) (*formatSelection, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := selectionKey{chatID, messageID}
+	selection, ok := s.selections[key]
+	if !ok || s.now().After(selection.expiresAt) {
+		delete(s.selections, key)
+		return nil, false
+	}
+
+	if !accept(selection) {
+		return nil, false
+	}
+
+	delete(s.selections, key)
+	if s.latest[chatID] == messageID {
+		delete(s.latest, chatID)
+	}
+	return selection, true
+}
+
+// clear removes all pickers of a chat
+func (s *selectionStore) clear(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.selections {
+		if key.chatID == chatID {
+			delete(s.selections, key)
+		}
+	}
+	delete(s.latest, chatID)
+}
@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"hamond.dev/telegram-bot-go/internal/youtube"
+)
+
+func TestSelectionStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := newSelectionStore()
+	store.now = func() time.Time { return now }
+
+	store.put(&formatSelection{
+		conv:      chatConversation(1),
+		video:     &youtube.VideoInfo{ID: "abc"},
+		formats:   []youtube.VideoFormat{{FormatID: "18"}, {FormatID: "136+140"}},
+		messageID: 10,
+	})
+
+	accept := func(s *formatSelection) bool { return true }
+	reject := func(s *formatSelection) bool { return false }
+
+	if _, ok := store.take(2, 10, accept); ok {
+		t.Error("Expected no selection for another chat")
+	}
+
+	if _, ok := store.take(1, 10, reject); ok {
+		t.Error("Expected rejected choice to fail")
+	}
+
+	selection, ok := store.take(1, 10, accept)
+	if !ok {
+		t.Fatal("Expected selection to be taken")
+	}
+
+	if format, ok := selection.format("136+140"); !ok || format.FormatID != "136+140" {
+		t.Errorf("Expected format 136+140, got %+v", format)
+	}
+
+	if _, ok := store.take(1, 10, accept); ok {
+		t.Error("Expected selection to be consumed by the first choice")
+	}
+
+	store.put(&formatSelection{conv: chatConversation(1), formats: []youtube.VideoFormat{{FormatID: "18"}}, messageID: 11})
+	now = now.Add(selectionTTL + time.Second)
+
+	if _, ok := store.get(1); ok {
+		t.Error("Expected selection to expire")
+	}
+}
+
+func TestSelectionStoreSeveralPickers(t *testing.T) {
+	store := newSelectionStore()
+	accept := func(s *formatSelection) bool { return true }
+
+	store.put(&formatSelection{conv: chatConversation(1), messageID: 10})
+	store.put(&formatSelection{conv: chatConversation(1), messageID: 11})
+
+	if latest, ok := store.get(1); !ok || latest.messageID != 11 {
+		t.Errorf("Expected the newest picker, got %+v", latest)
+	}
+
+	if _, ok := store.take(1, 10, accept); !ok {
+		t.Error("Expected the older picker to stay usable")
+	}
+	if latest, ok := store.get(1); !ok || latest.messageID != 11 {
+		t.Errorf("Expected the newest picker to be left, got %+v", latest)
+	}
+
+	store.clear(1)
+	if _, ok := store.take(1, 11, accept); ok {
+		t.Error("Expected clear to remove all pickers of the chat")
+	}
+}
@@ -1,78 +1,145 @@
 package bot
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"time"
 )
 
-// Server represents the webhook server
+const (
+	// serverShutdownTimeout limits how long shutdown waits for open requests
+	serverShutdownTimeout = 10 * time.Second
+	// secretTokenHeader carries the secret_token passed to setWebhook
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+)
+
+// Server represents the webhook server. Exported fields may be changed
+// before calling Run.
 type Server struct {
-	client *Client
-	port   string
+	handler     Handler
+	port        string
+	secretToken string
+	pool        *jobPool // Created by webhookHandler
+
+	MaxJobs int // Updates handled at the same time; more wait for a slot
 }
 
-// NewServer creates a new webhook server
-func NewServer(client *Client, port string) *Server {
+// NewServer creates a new webhook server passing updates to handler.
+// Requests must carry secretToken, which is passed to SetWebhook.
+func NewServer(handler Handler, port, secretToken string) *Server {
 	return &Server{
-		client: client,
-		port:   port,
+		handler:     handler,
+		port:        port,
+		secretToken: secretToken,
+		MaxJobs:     DefaultMaxJobs,
+	}
+}
+
+// NewSecretToken creates a random secret token for SetWebhook
+func NewSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to create secret token: %w", err)
 	}
+	return hex.EncodeToString(buf), nil
 }
 
-// Start starts the webhook server
-func (s *Server) Start() error {
-	http.HandleFunc("/webhook", s.webhookHandler)
-	http.HandleFunc("/health", s.healthHandler)
+// Run serves webhook requests until ctx is cancelled. Updates are handled
+// in the background on ctx rather than on the request, so a download
+// outlives the webhook call that started it. On shutdown Run waits for
+// the running updates to return.
+func (s *Server) Run(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook", s.webhookHandler(ctx))
+	mux.HandleFunc("/health", s.healthHandler)
+	server := &http.Server{Addr: ":" + s.port, Handler: mux}
 
 	fmt.Printf("Starting webhook server on port %s\n", s.port)
 	fmt.Printf("Webhook endpoint: /webhook\n")
 	fmt.Printf("Health check: /health\n")
 
-	return http.ListenAndServe(":"+s.port, nil)
-}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.ListenAndServe() }()
 
-// webhookHandler handles incoming webhook requests from Telegram
-func (s *Server) webhookHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
 	}
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		log.Printf("Error reading request body: %v", err)
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
-	}
+	// The main context is already cancelled
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
 
-	var update Update
-	if err := json.Unmarshal(body, &update); err != nil {
-		log.Printf("Error parsing JSON: %v", err)
-		http.Error(w, "Bad request", http.StatusBadRequest)
-		return
+	err := server.Shutdown(shutdownCtx)
+	s.pool.wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
+	return err
+}
+
+// webhookHandler handles incoming webhook requests from Telegram. The
+// update is acknowledged as soon as a job slot is free and handled on ctx.
+func (s *Server) webhookHandler(ctx context.Context) http.HandlerFunc {
+	s.pool = newJobPool(s.MaxJobs)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-	// Handle the message if it exists
-	if update.Message != nil {
-		fmt.Printf("Received webhook message from %s: %s\n",
-			update.Message.From.FirstName,
-			update.Message.Text)
+		// Only Telegram knows the secret; anyone else could fake senders
+		token := r.Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.secretToken)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
 
-		err := s.client.HandleMessage(update.Message)
+		body, err := io.ReadAll(r.Body)
 		if err != nil {
-			log.Printf("Error handling message: %v", err)
-			// Don't return error to Telegram, just log it
+			log.Printf("Error reading request body: %v", err)
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
 		}
-	}
 
-	// Always respond with 200 OK to Telegram
-	w.WriteHeader(http.StatusOK)
-	_, err = w.Write([]byte("OK"))
-	if err != nil {
-		log.Printf("Error writing response: %v", err)
+		var update Update
+		if err := json.Unmarshal(body, &update); err != nil {
+			log.Printf("Error parsing JSON: %v", err)
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		// Wait for a free slot while Telegram waits for the answer. If it
+		// gives up first, the update wasn't handled and is delivered again.
+		waitCtx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		defer context.AfterFunc(ctx, cancel)()
+		if err := s.pool.acquire(waitCtx); err != nil {
+			if ctx.Err() != nil {
+				http.Error(w, "Shutting down", http.StatusServiceUnavailable)
+			}
+			return
+		}
+
+		// Errors are only logged; Telegram would just deliver the update again
+		s.pool.run(func() { dispatch(ctx, s.handler, &update) })
+
+		// Always respond with 200 OK to Telegram
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write([]byte("OK"))
+		if err != nil {
+			log.Printf("Error writing response: %v", err)
+		}
 	}
 }
 
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newWebhookRequest creates an update request as Telegram sends it
+func newWebhookRequest(ctx context.Context, token string) *http.Request {
+	request := httptest.NewRequestWithContext(ctx, http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1}`))
+	request.Header.Set(secretTokenHeader, token)
+	return request
+}
+
+func TestWebhookHandlerOutlivesRequest(t *testing.T) {
+	release := make(chan struct{})
+	handled := make(chan error, 1)
+	server := NewServer(HandlerFunc(func(ctx context.Context, update *Update) error {
+		<-release
+		handled <- ctx.Err()
+		return nil
+	}), "0", "secret")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recorder := httptest.NewRecorder()
+	server.webhookHandler(ctx)(recorder, newWebhookRequest(context.Background(), "secret"))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the update to be acknowledged first, got status %d", recorder.Code)
+	}
+
+	close(release)
+	select {
+	case err := <-handled:
+		if err != nil {
+			t.Errorf("Expected the update to run after the request ended, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the update to be handled")
+	}
+	server.pool.wait()
+}
+
+func TestWebhookHandlerRejectsWrongSecret(t *testing.T) {
+	server := NewServer(HandlerFunc(func(ctx context.Context, update *Update) error {
+		t.Error("Expected forged update not to be handled")
+		return nil
+	}), "0", "secret")
+	handler := server.webhookHandler(context.Background())
+
+	for _, token := range []string{"", "guess"} {
+		recorder := httptest.NewRecorder()
+		handler(recorder, newWebhookRequest(context.Background(), token))
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("Expected token %q to be rejected, got status %d", token, recorder.Code)
+		}
+	}
+}
+
+func TestWebhookHandlerLimitsJobs(t *testing.T) {
+	release := make(chan struct{})
+	var calls int
+	server := NewServer(HandlerFunc(func(ctx context.Context, update *Update) error {
+		calls++
+		<-release
+		return nil
+	}), "0", "secret")
+	server.MaxJobs = 1
+	handler := server.webhookHandler(context.Background())
+
+	handler(httptest.NewRecorder(), newWebhookRequest(context.Background(), "secret"))
+
+	// The only slot is taken, so the next update waits until Telegram gives up
+	requestCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	recorder := httptest.NewRecorder()
+	handler(recorder, newWebhookRequest(requestCtx, "secret"))
+	if recorder.Code == http.StatusOK && recorder.Body.String() == "OK" {
+		t.Error("Expected the second update not to be acknowledged")
+	}
+
+	close(release)
+	server.pool.wait()
+	if calls != 1 {
+		t.Errorf("Expected one handled update, got %d", calls)
+	}
+}
@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"hamond.dev/telegram-bot-go/internal/markup"
+)
+
+// progressInterval throttles progress edits; Telegram limits how often a
+// message may be edited and every edit counts against the chat's rate limit
+const progressInterval = 3 * time.Second
+
+// statusMessage is a single message that is edited in place to report the
+// stages and progress of a long-running job
+type statusMessage struct {
+	client    *Client
+	chatID    int64
+	messageID int64
+
+	mu       sync.Mutex
+	header   string // Shown above every stage, e.g. the video title; formatted
+	text     string // Currently displayed text; formatted
+	keyboard bool   // Whether a keyboard is currently attached
+	lastEdit time.Time
+}
+
+// newStatus answers with a new status message with the given plain text
+func (c *Client) newStatus(ctx context.Context, conv conversation, text string) (*statusMessage, error) {
+	text = markup.Escape(parseMode, text)
+
+	message, err := c.Send(ctx, SendMessageRequest{
+		ChatID:          conv.chatID,
+		MessageThreadID: conv.threadID,
+		Text:            text,
+		ParseMode:       parseMode,
+		ReplyParameters: conv.replyParameters(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.statusFor(conv.chatID, message.MessageID, text), nil
+}
+
+// statusFor reuses an already sent message as status message
+func (c *Client) statusFor(chatID, messageID int64, text string) *statusMessage {
+	return &statusMessage{
+		client:    c,
+		chatID:    chatID,
+		messageID: messageID,
+		text:      text,
+	}
+}
+
+// SetHeader sets the formatted text shown above all following stages
+func (s *statusMessage) SetHeader(header *markup.Builder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.header = header.String()
+}
+
+// Set shows a new plain text stage and removes any keyboard
+func (s *statusMessage) Set(ctx context.Context, stage string) error {
+	return s.edit(ctx, s.render(stage), nil)
+}
+
+// SetWithKeyboard replaces the whole text with formatted text and
+// attaches a keyboard
+func (s *statusMessage) SetWithKeyboard(ctx context.Context, text string, keyboard *InlineKeyboardMarkup) error {
+	return s.edit(ctx, text, keyboard)
+}
+
+// Progress shows a stage with a progress bar. Updates arriving faster
+// than progressInterval are dropped, and errors are only logged since a
+// missed progress update is harmless.
+func (s *statusMessage) Progress(ctx context.Context, stage string, percent float64) {
+	s.mu.Lock()
+	tooSoon := time.Since(s.lastEdit) < progressInterval
+	s.mu.Unlock()
+
+	if tooSoon {
+		return
+	}
+
+	text := s.render(fmt.Sprintf("%s\n%s %.0f%%", stage, progressBar(percent), percent))
+	if err := s.edit(ctx, text, nil); err != nil {
+		log.Printf("Error updating progress: %v", err)
+	}
+}
+
+// Delete removes the status message
+func (s *statusMessage) Delete(ctx context.Context) error {
+	return s.client.DeleteMessage(ctx, s.chatID, s.messageID)
+}
+
+// render escapes a stage and places it below the header
+func (s *statusMessage) render(stage string) string {
+	stage = markup.Escape(parseMode, stage)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.header == "" {
+		return stage
+	}
+	return s.header + "\n\n" + stage
+}
+
+// edit updates the message unless it would not change
+func (s *statusMessage) edit(ctx context.Context, text string, keyboard *InlineKeyboardMarkup) error {
+	s.mu.Lock()
+	unchanged := text == s.text && keyboard == nil && !s.keyboard
+	s.mu.Unlock()
+
+	// Telegram rejects edits that change nothing
+	if unchanged {
+		return nil
+	}
+
+	_, err := s.client.EditMessageText(ctx, EditMessageTextRequest{
+		ChatID:      s.chatID,
+		MessageID:   s.messageID,
+		Text:        text,
+		ParseMode:   parseMode,
+		ReplyMarkup: keyboard,
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastEdit = time.Now()
+	if err == nil {
+		s.text = text
+		s.keyboard = keyboard != nil
+	}
+
+	return err
+}
+
+// progressBar draws a ten step bar for percent in [0, 100]
+func progressBar(percent float64) string {
+	filled := int(percent / 10)
+	filled = max(0, min(10, filled))
+
+	return strings.Repeat("▓", filled) + strings.Repeat("░", 10-filled)
+}
@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"hamond.dev/telegram-bot-go/internal/markup"
+)
+
+func TestStatusMessage(t *testing.T) {
+	var edits []EditMessageTextRequest
+	deleted := false
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			okHandler(t, `{"message_id":7,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+		case strings.HasSuffix(r.URL.Path, "/editMessageText"):
+			var req EditMessageTextRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			edits = append(edits, req)
+			okHandler(t, `{"message_id":7,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+		case strings.HasSuffix(r.URL.Path, "/deleteMessage"):
+			deleted = true
+			okHandler(t, `true`)(w, r)
+		}
+	})
+
+	ctx := context.Background()
+	status, err := client.newStatus(ctx, chatConversation(1), "Working...")
+	if err != nil {
+		t.Fatalf("newStatus() failed: %v", err)
+	}
+
+	status.SetHeader(markup.New(parseMode).Bold("Title"))
+	status.Set(ctx, "Downloading")
+	status.Set(ctx, "Downloading") // Unchanged, must not be sent
+	status.Progress(ctx, "Downloading", 50)
+
+	if len(edits) != 1 {
+		t.Fatalf("Expected 1 edit, got %d: %+v", len(edits), edits)
+	}
+
+	if edits[0].MessageID != 7 || edits[0].Text != "<b>Title</b>\n\nDownloading" || edits[0].ParseMode != markup.HTML {
+		t.Errorf("Unexpected edit: %+v", edits[0])
+	}
+
+	if err := status.Delete(ctx); err != nil || !deleted {
+		t.Errorf("Expected status message to be deleted, got %v", err)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := map[float64]string{
+		0:   "░░░░░░░░░░",
+		45:  "▓▓▓▓░░░░░░",
+		100: "▓▓▓▓▓▓▓▓▓▓",
+		150: "▓▓▓▓▓▓▓▓▓▓",
+	}
+
+	for percent, expected := range tests {
+		if got := progressBar(percent); got != expected {
+			t.Errorf("progressBar(%v) = %s, expected %s", percent, got, expected)
+		}
+	}
+}
@@ -1,5 +1,27 @@
 package bot
 
+import (
+	"encoding/json"
+	"unicode/utf16"
+
+	"hamond.dev/telegram-bot-go/internal/markup"
+)
+
+// APIResponse represents the envelope shared by all Bot API responses
+type APIResponse struct {
+	Ok          bool                `json:"ok"`
+	Result      json.RawMessage     `json:"result,omitempty"`
+	ErrorCode   int                 `json:"error_code,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Parameters  *ResponseParameters `json:"parameters,omitempty"`
+}
+
+// ResponseParameters describes why a request was unsuccessful
+type ResponseParameters struct {
+	MigrateToChatID int64 `json:"migrate_to_chat_id,omitempty"`
+	RetryAfter      int   `json:"retry_after,omitempty"`
+}
+
 // User represents a Telegram user or bot
 type User struct {
 	ID                      int64  `json:"id"`
@@ -13,20 +35,121 @@ type User struct {
 	SupportsInlineQueries   bool   `json:"supports_inline_queries,omitempty"`
 }
 
-// GetMeResponse represents the response from getMe API call
-type GetMeResponse struct {
-	Ok          bool   `json:"ok"`
-	Result      User   `json:"result"`
-	Description string `json:"description,omitempty"`
-}
-
 // Message represents a Telegram message
 type Message struct {
-	MessageID int64  `json:"message_id"`
-	From      *User  `json:"from,omitempty"`
-	Chat      Chat   `json:"chat"`
-	Date      int64  `json:"date"`
-	Text      string `json:"text,omitempty"`
+	MessageID       int64           `json:"message_id"`
+	MessageThreadID int64           `json:"message_thread_id,omitempty"` // Forum topic of the message
+	From            *User           `json:"from,omitempty"`              // Missing in channels
+	SenderChat      *Chat           `json:"sender_chat,omitempty"`       // Set for channel posts and anonymous admins
+	Chat            Chat            `json:"chat"`
+	Date            int64           `json:"date"`
+	EditDate        int64           `json:"edit_date,omitempty"`
+	IsTopicMessage  bool            `json:"is_topic_message,omitempty"`
+	ForwardOrigin   *MessageOrigin  `json:"forward_origin,omitempty"`
+	ReplyToMessage  *Message        `json:"reply_to_message,omitempty"`
+	Text            string          `json:"text,omitempty"`
+	Entities        []MessageEntity `json:"entities,omitempty"` // Entities in Text
+	Caption         string          `json:"caption,omitempty"`
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"` // Entities in Caption
+	Photo           []PhotoSize     `json:"photo,omitempty"`            // Available sizes, smallest first
+	Video           *Video          `json:"video,omitempty"`
+	Audio           *Audio          `json:"audio,omitempty"`
+	Voice           *Voice          `json:"voice,omitempty"`
+	Document        *Document       `json:"document,omitempty"`
+}
+
+// Types of message entities handled by the bot
+const (
+	EntityMention     = "mention"      // @username
+	EntityBotCommand  = "bot_command"  // /start@bot
+	EntityURL         = "url"          // https://example.com
+	EntityTextLink    = "text_link"    // Clickable text with URL
+	EntityTextMention = "text_mention" // Mention of a user without username
+)
+
+// MessageEntity marks a special part of a message text, like a link.
+// Offset and Length are measured in UTF-16 code units.
+type MessageEntity struct {
+	Type     string `json:"type"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	URL      string `json:"url,omitempty"`      // For text_link
+	User     *User  `json:"user,omitempty"`     // For text_mention
+	Language string `json:"language,omitempty"` // For pre
+}
+
+// entityText returns the part of text marked by entity, converting its
+// UTF-16 offsets. Entities outside of text yield an empty string.
+func entityText(text string, entity MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	end := entity.Offset + entity.Length
+	if entity.Offset < 0 || entity.Length < 0 || end > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[entity.Offset:end]))
+}
+
+// MessageOrigin describes where a forwarded message came from
+type MessageOrigin struct {
+	Type            string `json:"type"` // "user", "hidden_user", "chat" or "channel"
+	Date            int64  `json:"date"`
+	SenderUser      *User  `json:"sender_user,omitempty"`
+	SenderUserName  string `json:"sender_user_name,omitempty"`
+	SenderChat      *Chat  `json:"sender_chat,omitempty"`
+	Chat            *Chat  `json:"chat,omitempty"`
+	MessageID       int64  `json:"message_id,omitempty"`
+	AuthorSignature string `json:"author_signature,omitempty"`
+}
+
+// PhotoSize represents one size of a photo or a thumbnail
+type PhotoSize struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// Voice represents a voice note
+type Voice struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Duration     int    `json:"duration"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// Video represents a video file
+type Video struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Duration     int    `json:"duration"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// Audio represents a music file
+type Audio struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Duration     int    `json:"duration"`
+	Performer    string `json:"performer,omitempty"`
+	Title        string `json:"title,omitempty"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// Document represents a general file
+type Document struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
 }
 
 // Chat represents a Telegram chat
@@ -37,30 +160,312 @@ type Chat struct {
 	Username  string `json:"username,omitempty"`
 	FirstName string `json:"first_name,omitempty"`
 	LastName  string `json:"last_name,omitempty"`
+	IsForum   bool   `json:"is_forum,omitempty"` // Supergroup with topics
 }
 
-// Update represents an incoming update from Telegram
+// IsGroup reports whether the chat is a group or supergroup
+func (c Chat) IsGroup() bool {
+	return c.Type == "group" || c.Type == "supergroup"
+}
+
+// Update represents an incoming update from Telegram. At most one of the
+// optional fields is set.
 type Update struct {
-	UpdateID int64    `json:"update_id"`
-	Message  *Message `json:"message,omitempty"`
+	UpdateID           int64               `json:"update_id"`
+	Message            *Message            `json:"message,omitempty"`
+	EditedMessage      *Message            `json:"edited_message,omitempty"`
+	ChannelPost        *Message            `json:"channel_post,omitempty"`
+	EditedChannelPost  *Message            `json:"edited_channel_post,omitempty"`
+	InlineQuery        *InlineQuery        `json:"inline_query,omitempty"`
+	ChosenInlineResult *ChosenInlineResult `json:"chosen_inline_result,omitempty"`
+	CallbackQuery      *CallbackQuery      `json:"callback_query,omitempty"`
+	MyChatMember       *ChatMemberUpdated  `json:"my_chat_member,omitempty"`
+}
+
+// Update types, as used for allowed_updates
+const (
+	UpdateMessage            = "message"
+	UpdateEditedMessage      = "edited_message"
+	UpdateChannelPost        = "channel_post"
+	UpdateEditedChannelPost  = "edited_channel_post"
+	UpdateInlineQuery        = "inline_query"
+	UpdateChosenInlineResult = "chosen_inline_result"
+	UpdateCallbackQuery      = "callback_query"
+	UpdateMyChatMember       = "my_chat_member"
+)
+
+// HandledUpdates lists the update types Client.HandleUpdate routes
+var HandledUpdates = []string{
+	UpdateMessage,
+	UpdateEditedMessage,
+	UpdateChannelPost,
+	UpdateEditedChannelPost,
+	UpdateInlineQuery,
+	UpdateChosenInlineResult,
+	UpdateCallbackQuery,
+	UpdateMyChatMember,
+}
+
+// message returns the message of any message-like update
+func (u *Update) message() *Message {
+	switch {
+	case u.Message != nil:
+		return u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.ChannelPost != nil:
+		return u.ChannelPost
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost
+	default:
+		return nil
+	}
+}
+
+// From returns the user who caused the update, or nil if it has no sender
+func (u *Update) From() *User {
+	if message := u.message(); message != nil {
+		return message.From
+	}
+
+	switch {
+	case u.InlineQuery != nil:
+		return &u.InlineQuery.From
+	case u.ChosenInlineResult != nil:
+		return &u.ChosenInlineResult.From
+	case u.CallbackQuery != nil:
+		return &u.CallbackQuery.From
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.From
+	default:
+		return nil
+	}
+}
+
+// Chat returns the chat the update belongs to, or nil if there is none
+func (u *Update) Chat() *Chat {
+	if message := u.message(); message != nil {
+		return &message.Chat
+	}
+
+	switch {
+	case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
+		return &u.CallbackQuery.Message.Chat
+	case u.MyChatMember != nil:
+		return &u.MyChatMember.Chat
+	default:
+		return nil
+	}
+}
+
+// InlineQuery represents a query typed after the bot's username in any chat
+type InlineQuery struct {
+	ID       string `json:"id"`
+	From     User   `json:"from"`
+	Query    string `json:"query"`
+	Offset   string `json:"offset"`              // Offset of the results to return, for pagination
+	ChatType string `json:"chat_type,omitempty"` // Type of the chat the query was sent from
+}
+
+// ChosenInlineResult reports which inline result a user picked. It
+// requires inline feedback to be enabled with @BotFather.
+type ChosenInlineResult struct {
+	ResultID        string `json:"result_id"`
+	From            User   `json:"from"`
+	Query           string `json:"query"`
+	InlineMessageID string `json:"inline_message_id,omitempty"`
+}
+
+// Chat member statuses
+const (
+	MemberCreator       = "creator"
+	MemberAdministrator = "administrator"
+	MemberMember        = "member"
+	MemberRestricted    = "restricted"
+	MemberLeft          = "left"
+	MemberKicked        = "kicked"
+)
+
+// ChatMember describes a member of a chat. Only the fields common to all
+// statuses are modelled.
+type ChatMember struct {
+	Status    string `json:"status"` // One of the Member constants
+	User      User   `json:"user"`
+	UntilDate int64  `json:"until_date,omitempty"` // For restricted and kicked members
+	IsMember  bool   `json:"is_member,omitempty"`  // For restricted members
+}
+
+// IsPresent reports whether the member is part of the chat
+func (m ChatMember) IsPresent() bool {
+	switch m.Status {
+	case MemberLeft, MemberKicked:
+		return false
+	case MemberRestricted:
+		return m.IsMember
+	default:
+		return true
+	}
+}
+
+// ChatMemberUpdated represents a change of a chat member's status
+type ChatMemberUpdated struct {
+	Chat          Chat       `json:"chat"`
+	From          User       `json:"from"` // Who made the change
+	Date          int64      `json:"date"`
+	OldChatMember ChatMember `json:"old_chat_member"`
+	NewChatMember ChatMember `json:"new_chat_member"`
+}
+
+// CallbackQuery represents a tap on an inline keyboard button
+type CallbackQuery struct {
+	ID              string   `json:"id"`
+	From            User     `json:"from"`
+	Message         *Message `json:"message,omitempty"` // Missing for inline messages or when too old
+	InlineMessageID string   `json:"inline_message_id,omitempty"`
+	ChatInstance    string   `json:"chat_instance"`
+	Data            string   `json:"data,omitempty"`
+}
+
+// InlineKeyboardMarkup represents an inline keyboard attached to a message
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton represents one button of an inline keyboard.
+// Exactly one of URL or CallbackData must be set.
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"` // 1-64 bytes
 }
 
-// GetUpdatesResponse represents the response from getUpdates API call
-type GetUpdatesResponse struct {
-	Ok     bool     `json:"ok"`
-	Result []Update `json:"result"`
+// GetUpdatesRequest represents a request to get updates
+type GetUpdatesRequest struct {
+	Offset         int64    `json:"offset,omitempty"`
+	Limit          int      `json:"limit,omitempty"`
+	Timeout        int      `json:"timeout,omitempty"` // Long polling timeout in seconds
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
 }
 
 // SendMessageRequest represents a request to send a message
 type SendMessageRequest struct {
-	ChatID int64  `json:"chat_id"`
-	Text   string `json:"text"`
+	ChatID          int64                 `json:"chat_id"`
+	MessageThreadID int64                 `json:"message_thread_id,omitempty"` // Forum topic to send to
+	Text            string                `json:"text"`
+	ParseMode       markup.Mode           `json:"parse_mode,omitempty"` // Empty sends Text as is
+	ReplyParameters *ReplyParameters      `json:"reply_parameters,omitempty"`
+	ReplyMarkup     *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
 }
 
-// SendMessageResponse represents the response from sendMessage API call
-type SendMessageResponse struct {
-	Ok     bool    `json:"ok"`
-	Result Message `json:"result"`
+// ReplyParameters describes the message a new message replies to
+type ReplyParameters struct {
+	MessageID int64 `json:"message_id"`
+	// Send as a standalone message if the replied message was deleted
+	AllowSendingWithoutReply bool `json:"allow_sending_without_reply,omitempty"`
+}
+
+func (r SendMessageRequest) targetChatID() int64 { return r.ChatID }
+
+// SendVideoRequest represents a request to send a playable video
+type SendVideoRequest struct {
+	ChatID            int64            `json:"chat_id"`
+	MessageThreadID   int64            `json:"message_thread_id,omitempty"`
+	Video             InputFile        `json:"video"`
+	Duration          int              `json:"duration,omitempty"` // Seconds
+	Width             int              `json:"width,omitempty"`
+	Height            int              `json:"height,omitempty"`
+	Thumbnail         *InputFile       `json:"thumbnail,omitempty"` // JPEG, at most 320x320 and 200 kB
+	Caption           string           `json:"caption,omitempty"`
+	ParseMode         markup.Mode      `json:"parse_mode,omitempty"` // Applies to Caption
+	SupportsStreaming bool             `json:"supports_streaming,omitempty"`
+	ReplyParameters   *ReplyParameters `json:"reply_parameters,omitempty"`
+}
+
+func (r SendVideoRequest) targetChatID() int64 { return r.ChatID }
+
+func (r SendVideoRequest) inputFiles() map[string]*InputFile {
+	return map[string]*InputFile{"video": &r.Video, "thumbnail": r.Thumbnail}
+}
+
+// SendDocumentRequest represents a request to send a general file
+type SendDocumentRequest struct {
+	ChatID          int64            `json:"chat_id"`
+	MessageThreadID int64            `json:"message_thread_id,omitempty"`
+	Document        InputFile        `json:"document"`
+	Thumbnail       *InputFile       `json:"thumbnail,omitempty"`
+	Caption         string           `json:"caption,omitempty"`
+	ParseMode       markup.Mode      `json:"parse_mode,omitempty"` // Applies to Caption
+	ReplyParameters *ReplyParameters `json:"reply_parameters,omitempty"`
+}
+
+func (r SendDocumentRequest) targetChatID() int64 { return r.ChatID }
+
+func (r SendDocumentRequest) inputFiles() map[string]*InputFile {
+	return map[string]*InputFile{"document": &r.Document, "thumbnail": r.Thumbnail}
+}
+
+// SendAudioRequest represents a request to send a music file
+type SendAudioRequest struct {
+	ChatID          int64            `json:"chat_id"`
+	MessageThreadID int64            `json:"message_thread_id,omitempty"`
+	Audio           InputFile        `json:"audio"`
+	Duration        int              `json:"duration,omitempty"` // Seconds
+	Performer       string           `json:"performer,omitempty"`
+	Title           string           `json:"title,omitempty"`
+	Thumbnail       *InputFile       `json:"thumbnail,omitempty"`
+	Caption         string           `json:"caption,omitempty"`
+	ParseMode       markup.Mode      `json:"parse_mode,omitempty"` // Applies to Caption
+	ReplyParameters *ReplyParameters `json:"reply_parameters,omitempty"`
+}
+
+func (r SendAudioRequest) targetChatID() int64 { return r.ChatID }
+
+func (r SendAudioRequest) inputFiles() map[string]*InputFile {
+	return map[string]*InputFile{"audio": &r.Audio, "thumbnail": r.Thumbnail}
+}
+
+// EditMessageTextRequest represents a request to edit the text of a message
+type EditMessageTextRequest struct {
+	ChatID      int64                 `json:"chat_id"`
+	MessageID   int64                 `json:"message_id"`
+	Text        string                `json:"text"`
+	ParseMode   markup.Mode           `json:"parse_mode,omitempty"`
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"` // Nil removes the keyboard
+}
+
+func (r EditMessageTextRequest) targetChatID() int64 { return r.ChatID }
+
+// DeleteMessageRequest represents a request to delete a message
+type DeleteMessageRequest struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int64 `json:"message_id"`
+}
+
+func (r DeleteMessageRequest) targetChatID() int64 { return r.ChatID }
+
+// Chat actions shown at the top of a chat while the bot is busy
+const (
+	ChatActionTyping         = "typing"
+	ChatActionUploadVideo    = "upload_video"
+	ChatActionUploadDocument = "upload_document"
+)
+
+// SendChatActionRequest represents a request to show a chat action. It is
+// shown for 5 seconds or until the bot sends a message. Chat actions
+// aren't messages, so they don't count against the per-chat rate limit.
+type SendChatActionRequest struct {
+	ChatID          int64  `json:"chat_id"`
+	MessageThreadID int64  `json:"message_thread_id,omitempty"`
+	Action          string `json:"action"`
+}
+
+// AnswerCallbackQueryRequest represents a request to answer a callback query
+type AnswerCallbackQueryRequest struct {
+	CallbackQueryID string `json:"callback_query_id"`
+	Text            string `json:"text,omitempty"`
+	ShowAlert       bool   `json:"show_alert,omitempty"`
+	URL             string `json:"url,omitempty"`
+	CacheTime       int    `json:"cache_time,omitempty"`
 }
 
 // WebhookInfo represents webhook information
@@ -72,20 +477,116 @@ type WebhookInfo struct {
 	LastErrorMessage     string `json:"last_error_message,omitempty"`
 }
 
-// GetWebhookInfoResponse represents the response from getWebhookInfo
-type GetWebhookInfoResponse struct {
-	Ok     bool        `json:"ok"`
-	Result WebhookInfo `json:"result"`
-}
-
 // SetWebhookRequest represents a request to set webhook
 type SetWebhookRequest struct {
 	URL string `json:"url"`
+	// Sent back in the X-Telegram-Bot-Api-Secret-Token header of every
+	// webhook request; 1-256 characters of A-Z, a-z, 0-9, _ and -
+	SecretToken string `json:"secret_token,omitempty"`
+}
+
+// BotCommand is an entry of the command menu shown by Telegram clients
+type BotCommand struct {
+	Command     string `json:"command"` // Without the leading slash
+	Description string `json:"description"`
+}
+
+// Scopes of a command list, see BotCommandScope
+const (
+	ScopeDefault               = "default"
+	ScopeAllPrivateChats       = "all_private_chats"
+	ScopeAllGroupChats         = "all_group_chats"
+	ScopeAllChatAdministrators = "all_chat_administrators"
+	ScopeChat                  = "chat"
+	ScopeChatAdministrators    = "chat_administrators"
+	ScopeChatMember            = "chat_member"
+)
+
+// BotCommandScope selects the chats and users a command list applies to
+type BotCommandScope struct {
+	Type   string `json:"type"`              // One of the Scope constants
+	ChatID int64  `json:"chat_id,omitempty"` // For the chat scopes
+	UserID int64  `json:"user_id,omitempty"` // For ScopeChatMember
+}
+
+// SetMyCommandsRequest represents a request to change the command list
+type SetMyCommandsRequest struct {
+	Commands     []BotCommand     `json:"commands"`
+	Scope        *BotCommandScope `json:"scope,omitempty"`         // Nil means ScopeDefault
+	LanguageCode string           `json:"language_code,omitempty"` // Empty applies to all languages without their own list
+}
+
+// GetMyCommandsRequest represents a request for the current command list
+type GetMyCommandsRequest struct {
+	Scope        *BotCommandScope `json:"scope,omitempty"`
+	LanguageCode string           `json:"language_code,omitempty"`
+}
+
+// DeleteMyCommandsRequest represents a request to delete a command list
+type DeleteMyCommandsRequest struct {
+	Scope        *BotCommandScope `json:"scope,omitempty"`
+	LanguageCode string           `json:"language_code,omitempty"`
+}
+
+// AnswerInlineQueryRequest represents a request to answer an inline query
+type AnswerInlineQueryRequest struct {
+	InlineQueryID string              `json:"inline_query_id"`
+	Results       []InlineQueryResult `json:"results"`
+	CacheTime     int                 `json:"cache_time,omitempty"` // Seconds Telegram may cache the answer for the query
+	IsPersonal    bool                `json:"is_personal,omitempty"`
+	NextOffset    string              `json:"next_offset,omitempty"`
+}
+
+// InlineQueryResult is one of the InlineQueryResult types
+type InlineQueryResult interface {
+	inlineQueryResult()
+}
+
+// InlineQueryResultArticle is a result that sends a text message
+type InlineQueryResultArticle struct {
+	ID                  string                  `json:"id"`
+	Title               string                  `json:"title"`
+	InputMessageContent InputTextMessageContent `json:"input_message_content"`
+	URL                 string                  `json:"url,omitempty"`
+	Description         string                  `json:"description,omitempty"`
+	ThumbnailURL        string                  `json:"thumbnail_url,omitempty"`
+}
+
+func (InlineQueryResultArticle) inlineQueryResult() {}
+
+// MarshalJSON adds the result type
+func (r InlineQueryResultArticle) MarshalJSON() ([]byte, error) {
+	type result InlineQueryResultArticle
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		result
+	}{"article", result(r)})
+}
+
+// InlineQueryResultCachedVideo is a result that sends a video Telegram
+// already stores
+type InlineQueryResultCachedVideo struct {
+	ID          string      `json:"id"`
+	VideoFileID string      `json:"video_file_id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description,omitempty"`
+	Caption     string      `json:"caption,omitempty"`
+	ParseMode   markup.Mode `json:"parse_mode,omitempty"`
+}
+
+func (InlineQueryResultCachedVideo) inlineQueryResult() {}
+
+// MarshalJSON adds the result type
+func (r InlineQueryResultCachedVideo) MarshalJSON() ([]byte, error) {
+	type result InlineQueryResultCachedVideo
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		result
+	}{"video", result(r)})
 }
 
-// SetWebhookResponse represents the response from setWebhook
-type SetWebhookResponse struct {
-	Ok          bool   `json:"ok"`
-	Result      bool   `json:"result"`
-	Description string `json:"description,omitempty"`
+// InputTextMessageContent is the text message sent for an inline result
+type InputTextMessageContent struct {
+	MessageText string      `json:"message_text"`
+	ParseMode   markup.Mode `json:"parse_mode,omitempty"`
 }
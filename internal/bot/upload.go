@@ -0,0 +1,311 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// InputFile is a file to send: either a local file that is uploaded, or
+// a file_id or HTTP URL that Telegram already knows how to fetch
+type InputFile struct {
+	Path   string // Local file to upload
+	FileID string // file_id or URL, used when Path is empty
+}
+
+// MarshalJSON encodes the file reference. Local files are sent as
+// separate multipart parts, so they encode as an empty string here.
+func (f InputFile) MarshalJSON() ([]byte, error) {
+	if f.Path != "" {
+		return json.Marshal("")
+	}
+	return json.Marshal(f.FileID)
+}
+
+// uploader is implemented by requests that can carry files. inputFiles
+// maps form field names to files; nil entries are ignored.
+type uploader interface {
+	inputFiles() map[string]*InputFile
+}
+
+// localFiles returns the files of params that have to be uploaded
+func localFiles(params any) []formFile {
+	u, ok := params.(uploader)
+	if !ok {
+		return nil
+	}
+
+	var files []formFile
+	for field, file := range u.inputFiles() {
+		if file != nil && file.Path != "" {
+			files = append(files, formFile{field: field, path: file.Path})
+		}
+	}
+
+	// Map order is random; keep uploads deterministic
+	sort.Slice(files, func(i, j int) bool { return files[i].field < files[j].field })
+
+	return files
+}
+
+// encodeFields encodes params as a map of JSON values per field
+func encodeFields(params any) (map[string]json.RawMessage, error) {
+	jsonData, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// newLocalFileRequest encodes params as JSON, referring to local files by
+// file:// URI so a Local Bot API server can read them from disk
+func (c *Client) newLocalFileRequest(params any, files []formFile) (apiRequest, error) {
+	values, err := encodeFields(params)
+	if err != nil {
+		return apiRequest{}, err
+	}
+
+	for _, file := range files {
+		path, err := filepath.Abs(file.path)
+		if err != nil {
+			return apiRequest{}, err
+		}
+
+		uri, err := json.Marshal((&url.URL{Scheme: "file", Path: path}).String())
+		if err != nil {
+			return apiRequest{}, err
+		}
+		values[file.field] = uri
+	}
+
+	req, err := newJSONRequest(values)
+	if err != nil {
+		return apiRequest{}, err
+	}
+	// The server still has to send the file on to Telegram
+	req.timeout = c.uploadTimeout
+
+	if scoped, ok := params.(chatScoped); ok {
+		req.chatID = scoped.targetChatID()
+	}
+
+	return req, nil
+}
+
+// newUploadRequest encodes params as a multipart body: every JSON field
+// becomes a form field and local files become file parts
+func (c *Client) newUploadRequest(ctx context.Context, params any, files []formFile) (apiRequest, error) {
+	values, err := encodeFields(params)
+	if err != nil {
+		return apiRequest{}, err
+	}
+
+	for _, file := range files {
+		delete(values, file.field)
+	}
+
+	var fields []formField
+	for name, raw := range values {
+		// Strings are sent as is, everything else as JSON
+		value := string(raw)
+		var str string
+		if json.Unmarshal(raw, &str) == nil {
+			value = str
+		}
+		fields = append(fields, formField{name: name, value: value})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+
+	body, err := newMultipartBody(fields, files)
+	if err != nil {
+		return apiRequest{}, err
+	}
+
+	var chatID int64
+	if scoped, ok := params.(chatScoped); ok {
+		chatID = scoped.targetChatID()
+	}
+
+	return body.request(ctx, c.uploadTimeout, chatID), nil
+}
+
+// UploadProgressFunc receives the number of file bytes sent so far
+type UploadProgressFunc func(sent, total int64)
+
+// uploadProgressKey is the context key for the upload progress callback
+type uploadProgressKey struct{}
+
+// WithUploadProgress returns a context that reports the progress of file
+// uploads made with it to fn
+func WithUploadProgress(ctx context.Context, fn UploadProgressFunc) context.Context {
+	return context.WithValue(ctx, uploadProgressKey{}, fn)
+}
+
+// uploadProgress returns the progress callback stored in ctx, if any
+func uploadProgress(ctx context.Context) UploadProgressFunc {
+	fn, _ := ctx.Value(uploadProgressKey{}).(UploadProgressFunc)
+	return fn
+}
+
+// formField is a plain multipart form field
+type formField struct {
+	name  string
+	value string
+}
+
+// formFile is a multipart form field whose content is read from disk
+type formFile struct {
+	field string
+	path  string
+	size  int64
+}
+
+// multipartBody is a multipart/form-data body that is streamed from disk
+// instead of being buffered, so memory use doesn't grow with file size
+type multipartBody struct {
+	boundary string
+	fields   []formField
+	files    []formFile
+}
+
+// newMultipartBody prepares a body with the given fields and files
+func newMultipartBody(fields []formField, files []formFile) (*multipartBody, error) {
+	for i := range files {
+		info, err := os.Stat(files[i].path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open upload file: %w", err)
+		}
+		files[i].size = info.Size()
+	}
+
+	return &multipartBody{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+		fields:   fields,
+		files:    files,
+	}, nil
+}
+
+// contentType returns the Content-Type header value including the boundary
+func (b *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// contentLength computes the exact body size without reading any file by
+// writing the envelope only and adding the file sizes
+func (b *multipartBody) contentLength() int64 {
+	counter := &countingWriter{}
+	b.write(counter, func(io.Writer, formFile) error { return nil })
+
+	length := counter.n
+	for _, file := range b.files {
+		length += file.size
+	}
+	return length
+}
+
+// open starts streaming the body through a pipe. The writer goroutine
+// stops when the body is fully written or the reader is closed.
+func (b *multipartBody) open(onProgress UploadProgressFunc) io.ReadCloser {
+	reader, writer := io.Pipe()
+
+	var total int64
+	for _, file := range b.files {
+		total += file.size
+	}
+	progress := &progressWriter{total: total, onProgress: onProgress}
+
+	go func() {
+		err := b.write(writer, func(part io.Writer, file formFile) error {
+			f, err := os.Open(file.path)
+			if err != nil {
+				return fmt.Errorf("failed to open upload file: %w", err)
+			}
+			defer f.Close()
+
+			_, err = io.Copy(io.MultiWriter(part, progress), f)
+			return err
+		})
+		writer.CloseWithError(err)
+	}()
+
+	return reader
+}
+
+// write encodes the body to w, using copyFile to fill in file contents
+func (b *multipartBody) write(w io.Writer, copyFile func(io.Writer, formFile) error) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	for _, field := range b.fields {
+		if err := mw.WriteField(field.name, field.value); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range b.files {
+		part, err := mw.CreateFormFile(file.field, filepath.Base(file.path))
+		if err != nil {
+			return err
+		}
+		if err := copyFile(part, file); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+// request wraps the body into an upload request for chatID
+func (b *multipartBody) request(ctx context.Context, timeout time.Duration, chatID int64) apiRequest {
+	onProgress := uploadProgress(ctx)
+
+	return apiRequest{
+		contentType:   b.contentType(),
+		contentLength: b.contentLength(),
+		body:          func() io.Reader { return b.open(onProgress) },
+		timeout:       timeout,
+		chatID:        chatID,
+	}
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+// Write implements io.Writer
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// progressWriter reports the running total of bytes written to it
+type progressWriter struct {
+	sent       int64
+	total      int64
+	onProgress UploadProgressFunc
+}
+
+// Write implements io.Writer
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.sent += int64(len(p))
+	if w.onProgress != nil {
+		w.onProgress(w.sent, w.total)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSendVideoStreamsMultipart(t *testing.T) {
+	content := bytes.Repeat([]byte("video"), 100000)
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	thumbPath := filepath.Join(t.TempDir(), "thumb.jpg")
+	if err := os.WriteFile(thumbPath, []byte("jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var contentLength, received int64
+	var urlPath, chatID, duration, streaming, thumbnail, reply string
+	var fileData []byte
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		urlPath = r.URL.Path
+		contentLength = r.ContentLength
+		body, _ := io.ReadAll(r.Body)
+		received = int64(len(body))
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Failed to parse multipart body: %v", err)
+		} else {
+			chatID = r.FormValue("chat_id")
+			duration = r.FormValue("duration")
+			streaming = r.FormValue("supports_streaming")
+			reply = r.FormValue("reply_parameters")
+			file, _, _ := r.FormFile("video")
+			fileData, _ = io.ReadAll(file)
+			thumb, _, _ := r.FormFile("thumbnail")
+			data, _ := io.ReadAll(thumb)
+			thumbnail = string(data)
+		}
+
+		okHandler(t, `{"message_id":1,"chat":{"id":42,"type":"private"},"date":0}`)(w, r)
+	})
+
+	var lastSent, lastTotal int64
+	ctx := WithUploadProgress(context.Background(), func(sent, total int64) {
+		lastSent, lastTotal = sent, total
+	})
+
+	_, err := client.SendVideo(ctx, SendVideoRequest{
+		ChatID:            42,
+		Video:             InputFile{Path: path},
+		Duration:          212,
+		Thumbnail:         &InputFile{Path: thumbPath},
+		SupportsStreaming: true,
+		ReplyParameters:   &ReplyParameters{MessageID: 7, AllowSendingWithoutReply: true},
+	})
+	if err != nil {
+		t.Fatalf("SendVideo() failed: %v", err)
+	}
+
+	if !strings.HasSuffix(urlPath, "/sendVideo") {
+		t.Errorf("Expected sendVideo call, got %s", urlPath)
+	}
+
+	if duration != "212" || streaming != "true" {
+		t.Errorf("Expected duration 212 and supports_streaming true, got %q and %q", duration, streaming)
+	}
+
+	if reply != `{"message_id":7,"allow_sending_without_reply":true}` {
+		t.Errorf("Expected reply_parameters as JSON, got %q", reply)
+	}
+
+	if thumbnail != "jpeg" {
+		t.Errorf("Expected thumbnail upload, got %q", thumbnail)
+	}
+
+	if contentLength != received {
+		t.Errorf("Content-Length %d doesn't match body size %d", contentLength, received)
+	}
+
+	if chatID != "42" {
+		t.Errorf("Expected chat_id 42, got %q", chatID)
+	}
+
+	if !bytes.Equal(fileData, content) {
+		t.Errorf("Uploaded file doesn't match (%d of %d bytes)", len(fileData), len(content))
+	}
+
+	total := int64(len(content) + len("jpeg"))
+	if lastSent != total || lastTotal != total {
+		t.Errorf("Expected final progress %d/%d, got %d/%d", total, total, lastSent, lastTotal)
+	}
+}
+
+func TestSendVideoMissingFile(t *testing.T) {
+	client := newTestClient(t, okHandler(t, `true`))
+
+	_, err := client.SendVideo(context.Background(), SendVideoRequest{
+		ChatID: 1,
+		Video:  InputFile{Path: filepath.Join(t.TempDir(), "missing.mp4")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to open upload file") {
+		t.Errorf("Expected open error, got %v", err)
+	}
+}
+
+func TestSendVideoByFileID(t *testing.T) {
+	var contentType string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		okHandler(t, `{"message_id":1,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+	})
+
+	_, err := client.SendVideo(context.Background(), SendVideoRequest{
+		ChatID: 1,
+		Video:  InputFile{FileID: "BAACAgIAAxkBAAIB"},
+	})
+	if err != nil {
+		t.Fatalf("SendVideo() failed: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Expected a JSON request for file IDs, got %s", contentType)
+	}
+}
+
+func TestSendVideoLocalServerUsesFileURI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "video.mp4")
+
+	var contentType string
+	var body map[string]any
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&body)
+		okHandler(t, `{"message_id":1,"chat":{"id":1,"type":"private"},"date":0}`)(w, r)
+	}, WithLocalServer())
+
+	_, err := client.SendVideo(context.Background(), SendVideoRequest{
+		ChatID: 1,
+		Video:  InputFile{Path: path},
+	})
+	if err != nil {
+		t.Fatalf("SendVideo() failed: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Expected a JSON request in local mode, got %s", contentType)
+	}
+
+	if body["video"] != "file://"+path {
+		t.Errorf("Expected video file://%s, got %v", path, body["video"])
+	}
+}
+
+func TestUploadLimit(t *testing.T) {
+	if limit := NewClient("token").UploadLimit(); limit != 50*1024*1024 {
+		t.Errorf("Expected 50MB cloud limit, got %d", limit)
+	}
+
+	if limit := NewClient("token", WithLocalServer()).UploadLimit(); limit != 2000*1024*1024 {
+		t.Errorf("Expected 2000MB local limit, got %d", limit)
+	}
+}
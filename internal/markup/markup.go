@@ -0,0 +1,156 @@
+// Package markup formats Telegram messages. It escapes arbitrary text for
+// the HTML and MarkdownV2 parse modes and builds formatted messages.
+package markup
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Mode is a Telegram parse mode, sent as the parse_mode parameter
+type Mode string
+
+const (
+	// HTML is Telegram's HTML subset: <b>, <i>, <code>, <pre>, <a>, ...
+	HTML Mode = "HTML"
+	// MarkdownV2 is Telegram's markdown dialect that requires escaping of
+	// all special characters
+	MarkdownV2 Mode = "MarkdownV2"
+)
+
+// markdownV2Special lists the characters MarkdownV2 requires to be escaped
+// in ordinary text
+const markdownV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// Escape makes text safe to embed literally in a message sent with mode
+func Escape(mode Mode, text string) string {
+	switch mode {
+	case HTML:
+		return EscapeHTML(text)
+	case MarkdownV2:
+		return EscapeMarkdownV2(text)
+	default:
+		return text
+	}
+}
+
+// EscapeHTML escapes <, >, & and quotes for the HTML parse mode
+func EscapeHTML(text string) string {
+	return html.EscapeString(text)
+}
+
+// EscapeMarkdownV2 escapes every special character for MarkdownV2
+func EscapeMarkdownV2(text string) string {
+	return escapeChars(text, markdownV2Special)
+}
+
+// escapeChars prefixes each of chars in text with a backslash
+func escapeChars(text, chars string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range text {
+		if strings.ContainsRune(chars, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Builder assembles a formatted message. All text passed to it is
+// escaped, so titles and user input can never break the markup.
+type Builder struct {
+	mode Mode
+	b    strings.Builder
+}
+
+// New creates a builder for messages sent with mode
+func New(mode Mode) *Builder {
+	return &Builder{mode: mode}
+}
+
+// Mode returns the parse mode the message must be sent with
+func (b *Builder) Mode() Mode {
+	return b.mode
+}
+
+// Text appends plain text
+func (b *Builder) Text(text string) *Builder {
+	b.b.WriteString(Escape(b.mode, text))
+	return b
+}
+
+// Textf appends plain text formatted like fmt.Sprintf
+func (b *Builder) Textf(format string, args ...any) *Builder {
+	return b.Text(fmt.Sprintf(format, args...))
+}
+
+// Line appends text followed by a line break
+func (b *Builder) Line(text string) *Builder {
+	return b.Text(text + "\n")
+}
+
+// Bold appends bold text
+func (b *Builder) Bold(text string) *Builder {
+	return b.wrap("<b>", "</b>", "*", text)
+}
+
+// Italic appends italic text
+func (b *Builder) Italic(text string) *Builder {
+	return b.wrap("<i>", "</i>", "_", text)
+}
+
+// Code appends inline monospace text
+func (b *Builder) Code(text string) *Builder {
+	switch b.mode {
+	case HTML:
+		b.b.WriteString("<code>" + EscapeHTML(text) + "</code>")
+	case MarkdownV2:
+		// Inside code entities only ` and \ have to be escaped
+		b.b.WriteString("`" + escapeChars(text, "`\\") + "`")
+	default:
+		b.b.WriteString(text)
+	}
+	return b
+}
+
+// Link appends text linking to url
+func (b *Builder) Link(text, url string) *Builder {
+	switch b.mode {
+	case HTML:
+		b.b.WriteString(`<a href="` + EscapeHTML(url) + `">` + EscapeHTML(text) + "</a>")
+	case MarkdownV2:
+		// Inside the URL part only ) and \ have to be escaped
+		b.b.WriteString("[" + EscapeMarkdownV2(text) + "](" + escapeChars(url, ")\\") + ")")
+	default:
+		b.b.WriteString(text)
+	}
+	return b
+}
+
+// Raw appends already formatted text without escaping it
+func (b *Builder) Raw(formatted string) *Builder {
+	b.b.WriteString(formatted)
+	return b
+}
+
+// String returns the formatted message
+func (b *Builder) String() string {
+	return b.b.String()
+}
+
+// wrap appends escaped text between HTML tags or a MarkdownV2 delimiter
+func (b *Builder) wrap(open, close, delimiter, text string) *Builder {
+	switch b.mode {
+	case HTML:
+		b.b.WriteString(open + EscapeHTML(text) + close)
+	case MarkdownV2:
+		b.b.WriteString(delimiter + EscapeMarkdownV2(text) + delimiter)
+	default:
+		b.b.WriteString(text)
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+package markup
+
+import "testing"
+
+func TestEscapeHTML(t *testing.T) {
+	got := EscapeHTML(`Tom & Jerry <3 "live"`)
+	expected := "Tom &amp; Jerry &lt;3 &#34;live&#34;"
+	if got != expected {
+		t.Errorf("EscapeHTML() = %s, expected %s", got, expected)
+	}
+}
+
+func TestEscapeMarkdownV2(t *testing.T) {
+	tests := map[string]string{
+		"Hello World":          "Hello World",
+		"*bold* _it_":          `\*bold\* \_it\_`,
+		"1.5x (live) - remix!": `1\.5x \(live\) \- remix\!`,
+		`C:\path [a]{b}#+=|~>`: `C:\\path \[a\]\{b\}\#\+\=\|\~\>`,
+		"`code`":               "\\`code\\`",
+		"Привет, мир 👋":        "Привет, мир 👋",
+	}
+
+	for input, expected := range tests {
+		if got := EscapeMarkdownV2(input); got != expected {
+			t.Errorf("EscapeMarkdownV2(%q) = %s, expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestBuilderHTML(t *testing.T) {
+	got := New(HTML).
+		Bold("<Title>").Text(" & ").Italic("more").Line("").
+		Code("a<b").Text(" ").Link("watch", "https://youtu.be/x?a=1&b=2").
+		String()
+
+	expected := "<b>&lt;Title&gt;</b> &amp; <i>more</i>\n<code>a&lt;b</code> <a href=\"https://youtu.be/x?a=1&amp;b=2\">watch</a>"
+	if got != expected {
+		t.Errorf("Builder = %s, expected %s", got, expected)
+	}
+}
+
+func TestBuilderMarkdownV2(t *testing.T) {
+	got := New(MarkdownV2).
+		Bold("Title (1080p)").Text(" - ").Italic("v1.0").Line("").
+		Code("a`b").Text(" ").Link("watch!", "https://example.com/a_(b)").
+		String()
+
+	expected := "*Title \\(1080p\\)* \\- _v1\\.0_\n`a\\`b` [watch\\!](https://example.com/a_(b\\))"
+	if got != expected {
+		t.Errorf("Builder = %s, expected %s", got, expected)
+	}
+}
+
+func TestBuilderTextf(t *testing.T) {
+	b := New(HTML).Textf("%d < %d", 1, 2)
+	if b.String() != "1 &lt; 2" || b.Mode() != HTML {
+		t.Errorf("Textf() = %s (%s)", b.String(), b.Mode())
+	}
+}
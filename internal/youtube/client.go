@@ -1,28 +1,37 @@
 package youtube
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // Client handles YouTube operations
 type Client struct {
-	ytdlpPath string
+	ytdlpPath  string
+	httpClient *http.Client // Used for thumbnails
 }
 
 // NewClient creates a new YouTube client
 func NewClient() *Client {
 	return &Client{
-		ytdlpPath: "yt-dlp", // Assumes yt-dlp is in PATH
+		ytdlpPath:  "yt-dlp", // Assumes yt-dlp is in PATH
+		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
-// GetVideoInfo gets basic information about a YouTube video
-func (c *Client) GetVideoInfo(url string) (*VideoInfo, error) {
+// GetVideoInfo gets basic information about a YouTube video. Cancelling
+// ctx stops yt-dlp.
+func (c *Client) GetVideoInfo(ctx context.Context, url string) (*VideoInfo, error) {
 	// Run yt-dlp to get video info as JSON
-	cmd := exec.Command(c.ytdlpPath, "--print-json", "--no-download", url)
+	cmd := exec.CommandContext(ctx, c.ytdlpPath, "--print-json", "--no-download", url)
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -44,14 +53,77 @@ func (c *Client) IsValidURL(url string) bool {
 		(strings.Contains(url, "youtube.com") || strings.Contains(url, "youtu.be"))
 }
 
-// DownloadFormat18 downloads a video using format 18 (360p mp4 with audio)
-func (c *Client) DownloadFormat18(url, outputPath string) error {
-	// Run yt-dlp to download format 18
-	cmd := exec.Command(c.ytdlpPath, "-f", "18", "-o", outputPath, url)
+// DownloadFormat downloads a video in the given yt-dlp format. Merged
+// formats such as "137+140" are muxed into a single mp4 file. onProgress,
+// if not nil, is called for every progress line yt-dlp reports.
+// Cancelling ctx stops the download.
+func (c *Client) DownloadFormat(ctx context.Context, url, formatID, outputPath string, onProgress ProgressFunc) error {
+	err := c.download(ctx, onProgress,
+		"-f", formatID,
+		"--merge-output-format", "mp4",
+		"-o", outputPath,
+		url)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+
+	return nil
+}
+
+// DownloadAudio downloads the best audio stream and converts it to the
+// given format. Title, artist and cover art are embedded into the file.
+// outputPath should end in ".%(ext)s"; the extension is the format name.
+func (c *Client) DownloadAudio(ctx context.Context, url string, format AudioFormat, outputPath string, onProgress ProgressFunc) error {
+	err := c.download(ctx, onProgress,
+		"-f", "bestaudio",
+		"--extract-audio",
+		"--audio-format", string(format),
+		"--embed-metadata",
+		"--embed-thumbnail",
+		"--convert-thumbnails", "jpg",
+		"-o", outputPath,
+		url)
+	if err != nil {
+		return fmt.Errorf("failed to download audio: %w", err)
+	}
+
+	return nil
+}
+
+// download runs yt-dlp with the given arguments and reports progress
+func (c *Client) download(ctx context.Context, onProgress ProgressFunc, args ...string) error {
+	args = append([]string{
+		"--quiet", "--progress", "--newline",
+		"--progress-template", progressTemplate,
+	}, args...)
+	cmd := exec.CommandContext(ctx, c.ytdlpPath, args...)
+
+	// Only progress lines go to stdout; keep stderr for error reports
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 
-	output, err := cmd.CombinedOutput()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("failed to download video: %w (output: %s)", err, string(output))
+		return fmt.Errorf("failed to capture yt-dlp output: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start yt-dlp: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		progress, ok := parseProgressLine(scanner.Text())
+		if ok && onProgress != nil {
+			onProgress(progress)
+		}
+	}
+
+	// Drain whatever the scanner gave up on so yt-dlp never blocks
+	io.Copy(io.Discard, stdout)
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%w (output: %s)", err, stderr.String())
 	}
 
 	return nil
@@ -1,6 +1,7 @@
 package youtube
 
 import (
+	"context"
 	"testing"
 )
 
@@ -67,7 +68,7 @@ func TestGetVideoInfo(t *testing.T) {
 	// Test with a known working URL
 	url := "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
 
-	info, err := client.GetVideoInfo(url)
+	info, err := client.GetVideoInfo(context.Background(), url)
 	if err != nil {
 		t.Fatalf("GetVideoInfo() failed: %v", err)
 	}
@@ -103,7 +104,7 @@ func TestGetVideoInfoInvalidURL(t *testing.T) {
 	client := NewClient()
 
 	// Test with invalid URL
-	_, err := client.GetVideoInfo("https://www.google.com")
+	_, err := client.GetVideoInfo(context.Background(), "https://www.google.com")
 	if err == nil {
 		t.Error("Expected error for invalid URL, got nil")
 	}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"hamond.dev/telegram-bot-go/internal/markup"
 )
 
-// FilterMobileFriendlyFormats filters formats suitable for mobile devices
+// FilterMobileFriendlyFormats filters formats suitable for mobile devices.
+// It returns at most one format per quality, sorted from low to high.
 func FilterMobileFriendlyFormats(formats []VideoFormat) []VideoFormat {
 	var mobileFormats []VideoFormat
 
@@ -18,6 +21,9 @@ func FilterMobileFriendlyFormats(formats []VideoFormat) []VideoFormat {
 		"1080p": 1080,
 	}
 
+	// Best candidate seen so far for each quality
+	best := make(map[string]VideoFormat)
+
 	for _, format := range formats {
 		// Only include formats with both video and audio
 		if !format.HasVideo || !format.HasAudio {
@@ -29,12 +35,25 @@ func FilterMobileFriendlyFormats(formats []VideoFormat) []VideoFormat {
 			continue
 		}
 
+		// AV1 and VP9 in mp4 containers don't play on many phones
+		if format.VideoCodec != "" && !strings.HasPrefix(format.VideoCodec, "avc1") {
+			continue
+		}
+
 		// Only include mobile-friendly qualities
-		if _, isMobileFriendly := mobileQualities[format.Quality]; isMobileFriendly {
-			mobileFormats = append(mobileFormats, format)
+		if _, isMobileFriendly := mobileQualities[format.Quality]; !isMobileFriendly {
+			continue
+		}
+
+		if current, ok := best[format.Quality]; !ok || preferFormat(format, current) {
+			best[format.Quality] = format
 		}
 	}
 
+	for _, format := range best {
+		mobileFormats = append(mobileFormats, format)
+	}
+
 	// Sort by quality (ascending)
 	sort.Slice(mobileFormats, func(i, j int) bool {
 		qi := mobileQualities[mobileFormats[i].Quality]
@@ -45,6 +64,69 @@ func FilterMobileFriendlyFormats(formats []VideoFormat) []VideoFormat {
 	return mobileFormats
 }
 
+// preferFormat reports whether a is a better pick than b of the same quality
+func preferFormat(a, b VideoFormat) bool {
+	// Single files need no merging
+	if a.IsMerged() != b.IsMerged() {
+		return !a.IsMerged()
+	}
+
+	// Lower frame rates keep files small
+	if a.FPS != b.FPS {
+		return a.FPS < b.FPS
+	}
+
+	// Prefer known sizes, then smaller ones
+	if (a.FileSize == 0) != (b.FileSize == 0) {
+		return a.FileSize != 0
+	}
+	return a.FileSize < b.FileSize
+}
+
+// MergeAudioFormats adds a combined format for every video-only mp4
+// format, paired with the best m4a audio track. YouTube only serves
+// low qualities with audio included, so this is how 720p and 1080p
+// become downloadable as a single mp4.
+func MergeAudioFormats(formats []VideoFormat) []VideoFormat {
+	var audio *VideoFormat
+	for i, format := range formats {
+		if format.HasVideo || !format.HasAudio || format.Extension != "m4a" {
+			continue
+		}
+		if audio == nil || format.AudioBitrate > audio.AudioBitrate {
+			audio = &formats[i]
+		}
+	}
+
+	merged := append([]VideoFormat(nil), formats...)
+	if audio == nil {
+		return merged
+	}
+
+	for _, format := range formats {
+		if !format.HasVideo || format.HasAudio || format.Extension != "mp4" {
+			continue
+		}
+
+		combined := format
+		combined.FormatID = format.FormatID + "+" + audio.FormatID
+		combined.HasAudio = true
+		combined.AudioCodec = audio.AudioCodec
+		combined.AudioBitrate = audio.AudioBitrate
+
+		// The total size is only known if both parts are
+		if format.FileSize > 0 && audio.FileSize > 0 {
+			combined.FileSize = format.FileSize + audio.FileSize
+		} else {
+			combined.FileSize = 0
+		}
+
+		merged = append(merged, combined)
+	}
+
+	return merged
+}
+
 // FormatSizeToString converts bytes to human-readable format using decimal units
 func FormatSizeToString(bytes int64) string {
 	const (
@@ -64,15 +146,16 @@ func FormatSizeToString(bytes int64) string {
 	}
 }
 
-// CreateFormatMessage creates a user-friendly message showing available formats
-func CreateFormatMessage(videoTitle string, formats []VideoFormat) string {
+// CreateFormatMessage creates a user-friendly message showing available
+// formats, formatted for the given parse mode
+func CreateFormatMessage(mode markup.Mode, videoTitle string, formats []VideoFormat) string {
 	if len(formats) == 0 {
-		return "No mobile-friendly formats available for this video."
+		return markup.Escape(mode, "No mobile-friendly formats available for this video.")
 	}
 
-	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("📱 *%s*\n\n", videoTitle))
-	builder.WriteString("Available mobile-friendly formats:\n\n")
+	message := markup.New(mode)
+	message.Text("📱 ").Bold(videoTitle).Text("\n\n")
+	message.Line("Available mobile-friendly formats:\n")
 
 	for i, format := range formats {
 		sizeStr := "Unknown size"
@@ -80,11 +163,16 @@ func CreateFormatMessage(videoTitle string, formats []VideoFormat) string {
 			sizeStr = FormatSizeToString(format.FileSize)
 		}
 
-		builder.WriteString(fmt.Sprintf("%d. *%s* - %s (%s)\n",
-			i+1, format.Quality, sizeStr, format.Extension))
+		message.Textf("%d. ", i+1).Bold(format.Quality).Textf(" - %s (%s)\n", sizeStr, format.Extension)
 	}
 
-	builder.WriteString("\nSend the number of your preferred quality to download!")
+	message.Text("\nTap a button or send the number of your preferred quality to download!")
+
+	return message.String()
+}
 
-	return builder.String()
+// MobileFormats returns the mobile-friendly formats of the video,
+// including 720p and 1080p formats that need merging
+func (v *VideoInfo) MobileFormats() []VideoFormat {
+	return FilterMobileFriendlyFormats(MergeAudioFormats(v.Formats))
 }
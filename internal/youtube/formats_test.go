@@ -0,0 +1,90 @@
+package youtube
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// sampleInfo is a trimmed down yt-dlp --print-json output
+const sampleInfo = `{
+	"id": "dQw4w9WgXcQ",
+	"title": "Test video",
+	"duration": 212,
+	"formats": [
+		{"format_id": "140", "ext": "m4a", "vcodec": "none", "acodec": "mp4a.40.2", "abr": 129.5, "filesize": 3000000},
+		{"format_id": "139", "ext": "m4a", "vcodec": "none", "acodec": "mp4a.40.5", "abr": 48.8, "filesize": 1000000},
+		{"format_id": "18", "ext": "mp4", "vcodec": "avc1.42001E", "acodec": "mp4a.40.2", "width": 640, "height": 360, "fps": 25, "filesize_approx": 9000000},
+		{"format_id": "134", "ext": "mp4", "vcodec": "avc1.4d401e", "acodec": "none", "width": 640, "height": 360, "fps": 25, "filesize": 5000000},
+		{"format_id": "136", "ext": "mp4", "vcodec": "avc1.4d401f", "acodec": "none", "width": 1280, "height": 720, "fps": 25, "filesize": 20000000},
+		{"format_id": "298", "ext": "mp4", "vcodec": "avc1.4d4020", "acodec": "none", "width": 1280, "height": 720, "fps": 50, "filesize": 30000000},
+		{"format_id": "399", "ext": "mp4", "vcodec": "av01.0.08M.08", "acodec": "none", "width": 1920, "height": 1080, "fps": 25, "filesize": 40000000},
+		{"format_id": "137", "ext": "mp4", "vcodec": "avc1.640028", "acodec": "none", "width": 1920, "height": 1080, "fps": 25, "filesize": null},
+		{"format_id": "248", "ext": "webm", "vcodec": "vp9", "acodec": "none", "width": 1920, "height": 1080, "fps": 25, "filesize": 35000000}
+	]
+}`
+
+func TestVideoFormatUnmarshal(t *testing.T) {
+	var info VideoInfo
+	if err := json.Unmarshal([]byte(sampleInfo), &info); err != nil {
+		t.Fatalf("Failed to parse video info: %v", err)
+	}
+
+	if len(info.Formats) != 9 {
+		t.Fatalf("Expected 9 formats, got %d", len(info.Formats))
+	}
+
+	audio := info.Formats[0]
+	if audio.HasVideo || !audio.HasAudio || audio.Quality != "" {
+		t.Errorf("Expected audio-only format without quality, got %+v", audio)
+	}
+
+	progressive := info.Formats[2]
+	if !progressive.HasVideo || !progressive.HasAudio || progressive.Quality != "360p" {
+		t.Errorf("Expected 360p format with audio, got %+v", progressive)
+	}
+
+	if progressive.FileSize != 9000000 {
+		t.Errorf("Expected approximate size to be used, got %d", progressive.FileSize)
+	}
+}
+
+func TestMobileFormats(t *testing.T) {
+	var info VideoInfo
+	if err := json.Unmarshal([]byte(sampleInfo), &info); err != nil {
+		t.Fatalf("Failed to parse video info: %v", err)
+	}
+
+	formats := info.MobileFormats()
+
+	expected := []struct {
+		id       string
+		quality  string
+		fileSize int64
+	}{
+		{"18", "360p", 9000000},       // Progressive wins over merged 134+140
+		{"136+140", "720p", 23000000}, // Lower fps wins over 298
+		{"137+140", "1080p", 0},       // AV1 and webm are skipped, size unknown
+	}
+
+	if len(formats) != len(expected) {
+		t.Fatalf("Expected %d formats, got %d: %+v", len(expected), len(formats), formats)
+	}
+
+	for i, want := range expected {
+		got := formats[i]
+		if got.FormatID != want.id || got.Quality != want.quality || got.FileSize != want.fileSize {
+			t.Errorf("Format %d: expected %s/%s/%d, got %s/%s/%d",
+				i, want.id, want.quality, want.fileSize, got.FormatID, got.Quality, got.FileSize)
+		}
+	}
+}
+
+func TestParseAudioFormat(t *testing.T) {
+	if format, ok := ParseAudioFormat("MP3"); !ok || format != AudioMP3 {
+		t.Errorf("Expected mp3, got %q (ok=%v)", format, ok)
+	}
+
+	if _, ok := ParseAudioFormat("flac"); ok {
+		t.Error("Expected flac to be unsupported")
+	}
+}
@@ -0,0 +1,77 @@
+package youtube
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// progressPrefix marks the progress lines we ask yt-dlp to print
+const progressPrefix = "[progress]"
+
+// progressTemplate makes yt-dlp print one machine-readable line per
+// progress tick: downloaded, total, estimated total, speed and ETA.
+// Unknown values are printed as "NA".
+const progressTemplate = "download:" + progressPrefix +
+	" %(progress.downloaded_bytes)s" +
+	" %(progress.total_bytes)s" +
+	" %(progress.total_bytes_estimate)s" +
+	" %(progress.speed)s" +
+	" %(progress.eta)s"
+
+// Progress is a snapshot of a running download. Fields are zero when
+// yt-dlp doesn't know them yet. Merged formats are downloaded as two
+// files, so progress restarts once for the audio stream.
+type Progress struct {
+	Downloaded int64         // Bytes downloaded so far
+	Total      int64         // Total bytes, exact or estimated
+	Speed      float64       // Bytes per second
+	ETA        time.Duration // Estimated time left
+}
+
+// ProgressFunc receives progress updates while a download runs
+type ProgressFunc func(Progress)
+
+// Percent returns the completed percentage, or 0 if the total is unknown
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(100, float64(p.Downloaded)/float64(p.Total)*100)
+}
+
+// parseProgressLine parses a line printed with progressTemplate
+func parseProgressLine(line string) (Progress, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), progressPrefix)
+	if !ok {
+		return Progress{}, false
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) != 5 {
+		return Progress{}, false
+	}
+
+	progress := Progress{
+		Downloaded: int64(parseNumber(fields[0])),
+		Total:      int64(parseNumber(fields[1])),
+		Speed:      parseNumber(fields[3]),
+		ETA:        time.Duration(parseNumber(fields[4]) * float64(time.Second)),
+	}
+
+	// Fall back to the estimate when the exact size is unknown
+	if progress.Total == 0 {
+		progress.Total = int64(parseNumber(fields[2]))
+	}
+
+	return progress, true
+}
+
+// parseNumber parses a number printed by yt-dlp, treating "NA" as 0
+func parseNumber(value string) float64 {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
@@ -0,0 +1,60 @@
+package youtube
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected Progress
+		ok       bool
+	}{
+		{
+			name:     "Known total",
+			line:     "[progress] 1048576 4194304 NA 524288.5 6",
+			expected: Progress{Downloaded: 1048576, Total: 4194304, Speed: 524288.5, ETA: 6 * time.Second},
+			ok:       true,
+		},
+		{
+			name:     "Estimated total",
+			line:     "[progress] 1000 NA 8000.0 NA NA",
+			expected: Progress{Downloaded: 1000, Total: 8000},
+			ok:       true,
+		},
+		{
+			name: "Other output",
+			line: "[download] Destination: video.mp4",
+			ok:   false,
+		},
+		{
+			name: "Truncated line",
+			line: "[progress] 1000 2000",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress, ok := parseProgressLine(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("parseProgressLine(%q) ok = %v, expected %v", tt.line, ok, tt.ok)
+			}
+			if progress != tt.expected {
+				t.Errorf("parseProgressLine(%q) = %+v, expected %+v", tt.line, progress, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProgressPercent(t *testing.T) {
+	if percent := (Progress{Downloaded: 25, Total: 100}).Percent(); percent != 25 {
+		t.Errorf("Expected 25%%, got %v", percent)
+	}
+
+	if percent := (Progress{Downloaded: 25}).Percent(); percent != 0 {
+		t.Errorf("Expected 0%% for unknown total, got %v", percent)
+	}
+}
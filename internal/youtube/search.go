@@ -0,0 +1,76 @@
+package youtube
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// searchEntry is a search result as printed by yt-dlp --flat-playlist.
+// Flat entries carry fewer fields than VideoInfo and report the duration
+// as a float.
+type searchEntry struct {
+	ID         string      `json:"id"`
+	Title      string      `json:"title"`
+	URL        string      `json:"url"`
+	Duration   float64     `json:"duration"`
+	Channel    string      `json:"channel"`
+	Uploader   string      `json:"uploader"`
+	Thumbnails []Thumbnail `json:"thumbnails"`
+}
+
+// Search returns up to limit videos matching query, as found by
+// YouTube's search. Only the fields shown in result lists are filled in.
+func (c *Client) Search(ctx context.Context, query string, limit int) ([]VideoInfo, error) {
+	cmd := exec.CommandContext(ctx, c.ytdlpPath,
+		"--flat-playlist",
+		"--dump-json",
+		"--no-warnings",
+		fmt.Sprintf("ytsearch%d:%s", limit, query),
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search videos: %w", err)
+	}
+
+	return parseSearchResults(output)
+}
+
+// parseSearchResults decodes one JSON search entry per line
+func parseSearchResults(output []byte) ([]VideoInfo, error) {
+	var videos []VideoInfo
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry searchEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse search result: %w", err)
+		}
+
+		uploader := entry.Channel
+		if uploader == "" {
+			uploader = entry.Uploader
+		}
+
+		videos = append(videos, VideoInfo{
+			ID:         entry.ID,
+			Title:      entry.Title,
+			Duration:   int(entry.Duration),
+			Uploader:   uploader,
+			URL:        entry.URL,
+			Thumbnails: entry.Thumbnails,
+		})
+	}
+
+	return videos, scanner.Err()
+}
@@ -0,0 +1,32 @@
+package youtube
+
+import "testing"
+
+func TestParseSearchResults(t *testing.T) {
+	output := []byte(`{"id": "dQw4w9WgXcQ", "title": "Never Gonna Give You Up", "url": "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "duration": 212.0, "channel": "Rick Astley", "thumbnails": [{"url": "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg", "width": 168, "height": 94}]}
+
+{"id": "yPYZpwSpKmA", "title": "Together Forever", "url": "https://www.youtube.com/watch?v=yPYZpwSpKmA", "duration": null, "uploader": "RickAstleyVEVO"}
+`)
+
+	videos, err := parseSearchResults(output)
+	if err != nil {
+		t.Fatalf("parseSearchResults() failed: %v", err)
+	}
+
+	if len(videos) != 2 {
+		t.Fatalf("Expected 2 videos, got %d", len(videos))
+	}
+
+	first := videos[0]
+	if first.ID != "dQw4w9WgXcQ" || first.Duration != 212 || first.Uploader != "Rick Astley" || len(first.Thumbnails) != 1 {
+		t.Errorf("Unexpected first video: %+v", first)
+	}
+
+	if videos[1].Uploader != "RickAstleyVEVO" || videos[1].Duration != 0 {
+		t.Errorf("Expected uploader fallback and missing duration, got %+v", videos[1])
+	}
+
+	if _, err := parseSearchResults([]byte("not json")); err == nil {
+		t.Error("Expected an error for invalid output")
+	}
+}
@@ -0,0 +1,77 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// maxThumbnailSide is the largest width or height Telegram accepts for
+// a thumbnail
+const maxThumbnailSide = 320
+
+// SmallThumbnail returns the largest JPEG thumbnail that fits into
+// maxThumbnailSide, or false if the video has none
+func (v *VideoInfo) SmallThumbnail() (Thumbnail, bool) {
+	var best Thumbnail
+	found := false
+
+	for _, thumb := range v.Thumbnails {
+		if thumb.Width == 0 || thumb.Width > maxThumbnailSide || thumb.Height > maxThumbnailSide {
+			continue
+		}
+
+		// Telegram only accepts JPEG thumbnails
+		u, err := url.Parse(thumb.URL)
+		if err != nil || !strings.HasSuffix(u.Path, ".jpg") {
+			continue
+		}
+
+		if !found || thumb.Width > best.Width {
+			best = thumb
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// DownloadThumbnail saves the video's small JPEG thumbnail to path
+func (c *Client) DownloadThumbnail(ctx context.Context, info *VideoInfo, path string) error {
+	thumb, ok := info.SmallThumbnail()
+	if !ok {
+		return fmt.Errorf("no suitable thumbnail for video %s", info.ID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, thumb.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create thumbnail request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download thumbnail: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download thumbnail, status: %d", resp.StatusCode)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create thumbnail file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to save thumbnail: %w", err)
+	}
+
+	return nil
+}
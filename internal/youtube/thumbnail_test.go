@@ -0,0 +1,28 @@
+package youtube
+
+import "testing"
+
+func TestSmallThumbnail(t *testing.T) {
+	info := &VideoInfo{
+		Thumbnails: []Thumbnail{
+			{URL: "https://i.ytimg.com/vi/x/default.jpg", Width: 120, Height: 90},
+			{URL: "https://i.ytimg.com/vi/x/mqdefault.jpg?v=1", Width: 320, Height: 180},
+			{URL: "https://i.ytimg.com/vi_webp/x/mqdefault.webp", Width: 320, Height: 180},
+			{URL: "https://i.ytimg.com/vi/x/hqdefault.jpg", Width: 480, Height: 360},
+			{URL: "https://i.ytimg.com/vi/x/unknown.jpg"},
+		},
+	}
+
+	thumb, ok := info.SmallThumbnail()
+	if !ok {
+		t.Fatal("Expected a thumbnail")
+	}
+
+	if thumb.URL != "https://i.ytimg.com/vi/x/mqdefault.jpg?v=1" {
+		t.Errorf("Expected 320px JPEG thumbnail, got %s", thumb.URL)
+	}
+
+	if _, ok := (&VideoInfo{}).SmallThumbnail(); ok {
+		t.Error("Expected no thumbnail for a video without thumbnails")
+	}
+}
@@ -1,13 +1,51 @@
 package youtube
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 // VideoInfo represents basic video information
 type VideoInfo struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Duration    int    `json:"duration"`
-	Uploader    string `json:"uploader"`
-	Description string `json:"description"`
-	URL         string `json:"webpage_url"`
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Duration    int           `json:"duration"`
+	Uploader    string        `json:"uploader"`
+	Description string        `json:"description"`
+	URL         string        `json:"webpage_url"`
+	Formats     []VideoFormat `json:"formats,omitempty"`
+	Thumbnails  []Thumbnail   `json:"thumbnails,omitempty"`
+}
+
+// Thumbnail represents one of the preview images of a video
+type Thumbnail struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// AudioFormat is a target format for audio extraction
+type AudioFormat string
+
+// Supported audio formats
+const (
+	AudioM4A  AudioFormat = "m4a"
+	AudioMP3  AudioFormat = "mp3"
+	AudioOpus AudioFormat = "opus"
+)
+
+// AudioFormats lists the supported audio formats, default first
+var AudioFormats = []AudioFormat{AudioM4A, AudioMP3, AudioOpus}
+
+// ParseAudioFormat parses a case-insensitive audio format name
+func ParseAudioFormat(name string) (AudioFormat, bool) {
+	for _, format := range AudioFormats {
+		if strings.EqualFold(name, string(format)) {
+			return format, true
+		}
+	}
+	return "", false
 }
 
 // DownloadRequest represents a download request
@@ -26,15 +64,83 @@ type DownloadResult struct {
 
 // VideoFormat represents a video format/quality option
 type VideoFormat struct {
-	FormatID  string  `json:"format_id"`
-	Quality   string  `json:"quality"`
-	Extension string  `json:"ext"`
-	FileSize  int64   `json:"filesize,omitempty"`
-	HasVideo  bool    `json:"vcodec"` // Will be parsed from vcodec != "none"
-	HasAudio  bool    `json:"acodec"` // Will be parsed from acodec != "none"
-	Width     int     `json:"width,omitempty"`
-	Height    int     `json:"height,omitempty"`
-	FPS       float64 `json:"fps,omitempty"` // Changed from int to float64
+	FormatID     string  `json:"format_id"`
+	Quality      string  `json:"quality"`
+	Extension    string  `json:"ext"`
+	FileSize     int64   `json:"filesize,omitempty"`
+	HasVideo     bool    `json:"has_video"` // Parsed from vcodec != "none"
+	HasAudio     bool    `json:"has_audio"` // Parsed from acodec != "none"
+	VideoCodec   string  `json:"vcodec,omitempty"`
+	AudioCodec   string  `json:"acodec,omitempty"`
+	AudioBitrate float64 `json:"abr,omitempty"`
+	Width        int     `json:"width,omitempty"`
+	Height       int     `json:"height,omitempty"`
+	FPS          float64 `json:"fps,omitempty"` // Changed from int to float64
+}
+
+// IsMerged reports whether the format combines separate video and audio
+// streams, such as "137+140"
+func (f VideoFormat) IsMerged() bool {
+	return strings.Contains(f.FormatID, "+")
+}
+
+// UnmarshalJSON parses a format entry as reported by yt-dlp
+func (f *VideoFormat) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		FormatID       string   `json:"format_id"`
+		Extension      string   `json:"ext"`
+		FileSize       *float64 `json:"filesize"`
+		FileSizeApprox *float64 `json:"filesize_approx"`
+		VideoCodec     *string  `json:"vcodec"`
+		AudioCodec     *string  `json:"acodec"`
+		AudioBitrate   *float64 `json:"abr"`
+		Width          *int     `json:"width"`
+		Height         *int     `json:"height"`
+		FPS            *float64 `json:"fps"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*f = VideoFormat{
+		FormatID:     raw.FormatID,
+		Extension:    raw.Extension,
+		FileSize:     int64(deref(raw.FileSize)),
+		VideoCodec:   deref(raw.VideoCodec),
+		AudioCodec:   deref(raw.AudioCodec),
+		AudioBitrate: deref(raw.AudioBitrate),
+		Width:        deref(raw.Width),
+		Height:       deref(raw.Height),
+		FPS:          deref(raw.FPS),
+	}
+
+	// Fall back to yt-dlp's estimate when the exact size is unknown
+	if f.FileSize == 0 {
+		f.FileSize = int64(deref(raw.FileSizeApprox))
+	}
+
+	f.HasVideo = f.VideoCodec != "" && f.VideoCodec != "none"
+	f.HasAudio = f.AudioCodec != "" && f.AudioCodec != "none"
+
+	// Name the quality after the short side so portrait videos match too
+	if f.HasVideo && f.Height > 0 {
+		side := f.Height
+		if f.Width > 0 && f.Width < side {
+			side = f.Width
+		}
+		f.Quality = fmt.Sprintf("%dp", side)
+	}
+
+	return nil
+}
+
+// deref returns the value p points to, or the zero value for nil
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
 }
 
 // FormatSelectionRequest represents a user's format choice
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
-	"time"
 
 	"hamond.dev/telegram-bot-go/config"
 	"hamond.dev/telegram-bot-go/internal/bot"
@@ -19,11 +20,29 @@ func main() {
 		log.Fatal("TELEGRAM_BOT_TOKEN is not set")
 	}
 
+	// Remember uploaded files so repeated requests are answered instantly
+	fileCache, err := bot.NewJSONFileCache(filepath.Join(cfg.DataDir, "files.json"))
+	if err != nil {
+		log.Fatalf("Failed to open file cache: %v", err)
+	}
+
+	opts := []bot.Option{
+		bot.WithAPIURL(cfg.APIURL),
+		bot.WithFileCache(fileCache),
+	}
+	if cfg.LocalBotAPI {
+		opts = append(opts, bot.WithLocalServer())
+	}
+
 	// Create bot client
-	botClient := bot.NewClient(cfg.TelegramBotToken)
+	botClient := bot.NewClient(cfg.TelegramBotToken, opts...)
+
+	// Cancel all in-flight requests on shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Test the connection
-	user, err := botClient.GetMe()
+	user, err := botClient.GetMe(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get bot info: %v", err)
 	}
@@ -32,66 +51,66 @@ func main() {
 	fmt.Printf("Bot Name: %s\n", user.FirstName)
 	fmt.Printf("Bot Username: @%s\n", user.Username)
 	fmt.Printf("Mode: %s\n", cfg.Mode)
+	if cfg.LocalBotAPI {
+		fmt.Printf("Local Bot API: %s (upload limit %dMB)\n", cfg.APIURL, botClient.UploadLimit()/1024/1024)
+	}
 
-	// Handle graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Offer the commands in the clients' command menu
+	updated, err := botClient.SyncCommands(ctx, filepath.Join(cfg.DataDir, "commands.json"))
+	if err != nil {
+		log.Printf("Warning: Failed to sync commands: %v", err)
+	} else if updated {
+		fmt.Println("Command menu updated")
+	}
 
 	// Start bot based on mode
 	switch cfg.Mode {
 	case "webhook":
-		startWebhookMode(botClient, cfg, sigChan)
+		startWebhookMode(ctx, botClient, cfg)
 	case "polling":
-		startPollingMode(botClient, sigChan)
+		startPollingMode(ctx, botClient, cfg)
 	default:
 		log.Fatalf("Invalid mode: %s. Use 'polling' or 'webhook'", cfg.Mode)
 	}
 }
 
-func startPollingMode(botClient *bot.Client, sigChan chan os.Signal) {
+// newHandler wraps the bot in the middleware shared by polling and
+// webhook mode
+func newHandler(botClient *bot.Client, cfg *config.Config) bot.Handler {
+	middleware := []bot.Middleware{
+		bot.ReportErrors(botClient, cfg.AdminChatID),
+		bot.Recover(),
+		bot.UpdateContext(),
+		bot.Logging(),
+		bot.Timing(),
+	}
+	if len(cfg.AllowedUsers) > 0 {
+		middleware = append(middleware, bot.AllowUsers(cfg.AllowedUsers...))
+	}
+
+	return bot.Chain(botClient, middleware...)
+}
+
+func startPollingMode(ctx context.Context, botClient *bot.Client, cfg *config.Config) {
 	fmt.Println("Starting in polling mode...")
 	fmt.Println("Waiting for messages... (Press Ctrl+C to stop)")
 
-	var offset int64 = 0
-
-	// Start polling in a goroutine
-	go func() {
-		for {
-			updates, err := botClient.GetUpdates(offset)
-			if err != nil {
-				log.Printf("Error getting updates: %v", err)
-				time.Sleep(1 * time.Second)
-				continue
-			}
-
-			for _, update := range updates {
-				// Update offset to avoid getting the same update again
-				offset = update.UpdateID + 1
-
-				// Handle the message if it exists
-				if update.Message != nil {
-					fmt.Printf("Received message from %s: %s\n",
-						update.Message.From.FirstName,
-						update.Message.Text)
-
-					err := botClient.HandleMessage(update.Message)
-					if err != nil {
-						log.Printf("Error handling message: %v", err)
-					}
-				}
-			}
-
-			// Small delay to avoid excessive API calls
-			time.Sleep(1 * time.Second)
+	poller := bot.NewPoller(botClient, newHandler(botClient, cfg))
+	poller.AllowedUpdates = bot.HandledUpdates
+	poller.Store = bot.NewFileOffsetStore(filepath.Join(cfg.DataDir, "offset"))
+
+	// Poll until shutdown signal
+	if err := poller.Run(ctx); err != nil {
+		if errors.Is(err, bot.ErrWebhookActive) {
+			log.Fatalf("Polling stopped: %v (set MODE=webhook or remove the webhook first)", err)
 		}
-	}()
+		log.Fatalf("Polling stopped: %v", err)
+	}
 
-	// Wait for shutdown signal
-	<-sigChan
 	fmt.Println("\nShutting down bot...")
 }
 
-func startWebhookMode(botClient *bot.Client, cfg *config.Config, sigChan chan os.Signal) {
+func startWebhookMode(ctx context.Context, botClient *bot.Client, cfg *config.Config) {
 	if cfg.WebhookURL == "" {
 		log.Fatal("WEBHOOK_URL must be set for webhook mode")
 	}
@@ -100,39 +119,39 @@ func startWebhookMode(botClient *bot.Client, cfg *config.Config, sigChan chan os
 	fmt.Printf("Webhook URL: %s\n", cfg.WebhookURL)
 
 	// Delete any existing webhook first
-	err := botClient.DeleteWebhook()
+	err := botClient.DeleteWebhook(ctx)
 	if err != nil {
 		log.Printf("Warning: Failed to delete existing webhook: %v", err)
 	}
 
 	// Set the new webhook
 	webhookEndpoint := cfg.WebhookURL + "/webhook"
-	err = botClient.SetWebhook(webhookEndpoint)
+	// Telegram sends the secret with every update, so forged requests to
+	// the public endpoint can be told apart
+	secretToken, err := bot.NewSecretToken()
+	if err != nil {
+		log.Fatalf("Failed to set webhook: %v", err)
+	}
+	err = botClient.SetWebhook(ctx, bot.SetWebhookRequest{URL: webhookEndpoint, SecretToken: secretToken})
 	if err != nil {
 		log.Fatalf("Failed to set webhook: %v", err)
 	}
 
 	fmt.Printf("Webhook set successfully to: %s\n", webhookEndpoint)
 
-	// Create and start the server
-	server := bot.NewServer(botClient, cfg.Port)
-
-	// Start server in a goroutine
-	go func() {
-		if err := server.Start(); err != nil {
-			log.Fatalf("Server failed to start: %v", err)
-		}
-	}()
-
-	fmt.Printf("Server started on port %s\n", cfg.Port)
+	// Serve until shutdown signal; running updates are finished first
+	server := bot.NewServer(newHandler(botClient, cfg), cfg.Port, secretToken)
 	fmt.Println("Waiting for webhook updates... (Press Ctrl+C to stop)")
-
-	// Wait for shutdown signal
-	<-sigChan
+	if err := server.Run(ctx); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
 	fmt.Println("\nShutting down webhook...")
 
-	// Clean up webhook on shutdown
-	err = botClient.DeleteWebhook()
+	// Clean up webhook on shutdown; the main context is already cancelled
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), bot.DefaultTimeout)
+	defer cancel()
+
+	err = botClient.DeleteWebhook(cleanupCtx)
 	if err != nil {
 		log.Printf("Warning: Failed to delete webhook on shutdown: %v", err)
 	} else {